@@ -0,0 +1,359 @@
+// Package clsag implements concise linkable spontaneous anonymous group signatures.
+// A CLSAG signature proves knowledge of the private key of an output in the ring
+// together with the opening of its commitment to zero, while producing a single
+// response per ring member. The keys are aggregated with hashed coefficients,
+// so the signature is roughly half the size of a dual key MLSAG.
+package clsag
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/mlsag"
+	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+)
+
+// MaxRingSize is the maximum number of ring members accepted when decoding a signature
+const MaxRingSize = mlsag.MaxRingSize
+
+var transcriptLabel = []byte("vosbor.clsag")
+
+// Signature is a CLSAG signature
+type Signature struct {
+	c ristretto.Scalar
+	s []ristretto.Scalar
+
+	// KeyImage is the key image of the signers primary key
+	KeyImage ristretto.Point
+
+	// D is the auxiliary key image of the signers commitment to zero
+	D ristretto.Point
+
+	// Members of the ring, including the signer
+	Members []Member
+
+	Msg []byte
+}
+
+func (proof *Proof) prove() (*Signature, error) {
+
+	numMembers := len(proof.members)
+	signer := proof.members[proof.index]
+
+	var hP ristretto.Point
+	hP.Derive(signer.PubKey.Bytes())
+
+	keyImage := mlsag.CalculateKeyImage(proof.primaryKey, signer.PubKey)
+
+	// D = z * H(P)
+	var D ristretto.Point
+	D.ScalarMult(&hP, &proof.commToZero)
+
+	ctx := newContext(proof.msg, proof.members, keyImage, D)
+
+	// The signer proves knowledge of the aggregated key w = muP * p + muC * z
+	var w ristretto.Scalar
+	w.Mul(&ctx.muP, &proof.primaryKey)
+	w.MulAdd(&ctx.muC, &proof.commToZero, &w)
+
+	var alpha ristretto.Scalar
+	alpha.Rand()
+
+	// L = alpha * G, R = alpha * H(P)
+	var L, R ristretto.Point
+	L.ScalarMultBase(&alpha)
+	R.ScalarMult(&hP, &alpha)
+
+	responses := make([]ristretto.Scalar, numMembers)
+	challenges := make([]ristretto.Scalar, numMembers)
+
+	challenge := ctx.challenge(proof.index, L, R)
+
+	for k := 1; k < numMembers; k++ {
+		i := (proof.index + k) % numMembers
+		challenges[i] = challenge
+
+		responses[i].Rand()
+		L, R = ctx.commitments(proof.members[i], responses[i], challenge)
+		challenge = ctx.challenge(i, L, R)
+	}
+	challenges[proof.index] = challenge
+
+	// s = alpha - c * w
+	var s ristretto.Scalar
+	s.Mul(&challenge, &w)
+	s.Sub(&alpha, &s)
+	responses[proof.index] = s
+
+	return &Signature{
+		c:        challenges[0],
+		s:        responses,
+		KeyImage: keyImage,
+		D:        D,
+		Members:  proof.members,
+		Msg:      proof.msg,
+	}, nil
+}
+
+// Verify checks that the signature was created by a member of the ring
+// with the given key image
+func (sig *Signature) Verify(keyImage ristretto.Point) (bool, error) {
+
+	numMembers := len(sig.Members)
+	if numMembers == 0 || len(sig.s) != numMembers {
+		return false, errors.New("number of responses does not match the number of members")
+	}
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if keyImage.Equals(&zero) || sig.D.Equals(&zero) {
+		return false, errors.New("key image and auxiliary key image cannot be the identity")
+	}
+
+	ctx := newContext(sig.Msg, sig.Members, keyImage, sig.D)
+
+	challenge := sig.c
+	for i := 0; i < numMembers; i++ {
+		L, R := ctx.commitments(sig.Members[i], sig.s[i], challenge)
+		challenge = ctx.challenge(i, L, R)
+	}
+
+	if !challenge.Equals(&sig.c) {
+		return false, fmt.Errorf("c'0 does not equal c0, %s != %s", challenge.String(), sig.c.String())
+	}
+	return true, nil
+}
+
+// context holds the transcript of the ring and the aggregation coefficients
+type context struct {
+	transcript *fiatshamir.Transcript
+
+	// aggregation coefficients for the output keys and the commitments
+	muP, muC ristretto.Scalar
+
+	// aggregated key image muP * I + muC * D
+	aggImage ristretto.Point
+}
+
+func newContext(msg []byte, members []Member, keyImage, D ristretto.Point) *context {
+
+	t := fiatshamir.NewTranscript(transcriptLabel)
+	t.AppendMessage([]byte("msg"), msg)
+
+	t.AppendUint64([]byte("members"), uint64(len(members)))
+	for i := range members {
+		t.AppendPoint([]byte("pubkey"), members[i].PubKey)
+		t.AppendPoint([]byte("commitment"), members[i].Commitment)
+	}
+	t.AppendPoint([]byte("key-image"), keyImage)
+	t.AppendPoint([]byte("aux-key-image"), D)
+
+	agg := t.Clone()
+	ctx := &context{
+		transcript: t,
+		muP:        agg.ChallengeScalar([]byte("mu-P")),
+		muC:        agg.ChallengeScalar([]byte("mu-C")),
+	}
+
+	var muCD ristretto.Point
+	ctx.aggImage.ScalarMult(&keyImage, &ctx.muP)
+	muCD.ScalarMult(&D, &ctx.muC)
+	ctx.aggImage.Add(&ctx.aggImage, &muCD)
+
+	return ctx
+}
+
+// commitments computes L = s * G + c * W and R = s * H(P) + c * (muP * I + muC * D)
+// where W = muP * P + muC * C is the aggregated key of the member
+func (ctx *context) commitments(m Member, s, c ristretto.Scalar) (ristretto.Point, ristretto.Point) {
+
+	var W, muCC ristretto.Point
+	W.PublicScalarMult(&m.PubKey, &ctx.muP)
+	muCC.PublicScalarMult(&m.Commitment, &ctx.muC)
+	W.Add(&W, &muCC)
+
+	var L, cW ristretto.Point
+	L.ScalarMultBase(&s)
+	cW.PublicScalarMult(&W, &c)
+	L.Add(&L, &cW)
+
+	var hP, R, cI ristretto.Point
+	hP.Derive(m.PubKey.Bytes())
+	R.ScalarMult(&hP, &s)
+	cI.PublicScalarMult(&ctx.aggImage, &c)
+	R.Add(&R, &cI)
+
+	return L, R
+}
+
+// challenge derives the challenge of the member following index
+func (ctx *context) challenge(index int, L, R ristretto.Point) ristretto.Scalar {
+	t := ctx.transcript.Clone()
+	t.AppendUint64([]byte("index"), uint64(index))
+	t.AppendPoint([]byte("L"), L)
+	t.AppendPoint([]byte("R"), R)
+	return t.ChallengeScalar([]byte("challenge"))
+}
+
+// Encode writes the signature, including the key image.
+// The ring members are written only when encodeKeys is set
+func (sig *Signature) Encode(w io.Writer, encodeKeys bool) error {
+
+	if len(sig.s) > MaxRingSize {
+		return fmt.Errorf("ring size %d exceeds the maximum of %d", len(sig.s), MaxRingSize)
+	}
+	if encodeKeys && len(sig.Members) != len(sig.s) {
+		return errors.New("number of responses does not match the number of members")
+	}
+
+	err := binary.Write(w, binary.BigEndian, sig.c.Bytes())
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, sig.KeyImage.Bytes())
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, sig.D.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(len(sig.s)))
+	if err != nil {
+		return err
+	}
+	for i := range sig.s {
+		err = binary.Write(w, binary.BigEndian, sig.s[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	if !encodeKeys {
+		return nil
+	}
+
+	for i := range sig.Members {
+		err = binary.Write(w, binary.BigEndian, sig.Members[i].PubKey.Bytes())
+		if err != nil {
+			return err
+		}
+		err = binary.Write(w, binary.BigEndian, sig.Members[i].Commitment.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a signature written with Encode
+func (sig *Signature) Decode(r io.Reader, decodeKeys bool) error {
+
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	err := readerToScalar(r, &sig.c)
+	if err != nil {
+		return err
+	}
+	err = readerToPoint(r, &sig.KeyImage)
+	if err != nil {
+		return err
+	}
+	err = readerToPoint(r, &sig.D)
+	if err != nil {
+		return err
+	}
+
+	var numMembers uint32
+	err = binary.Read(r, binary.BigEndian, &numMembers)
+	if err != nil {
+		return err
+	}
+	if numMembers > MaxRingSize {
+		return fmt.Errorf("ring size %d exceeds the maximum of %d", numMembers, MaxRingSize)
+	}
+
+	sig.s = make([]ristretto.Scalar, numMembers)
+	for i := range sig.s {
+		err = readerToScalar(r, &sig.s[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	if !decodeKeys {
+		return nil
+	}
+
+	sig.Members = make([]Member, numMembers)
+	for i := range sig.Members {
+		err = readerToPoint(r, &sig.Members[i].PubKey)
+		if err != nil {
+			return err
+		}
+		err = readerToPoint(r, &sig.Members[i].Commitment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both signatures are the same
+func (sig Signature) Equals(other Signature, includeKeys bool) bool {
+	if !sig.c.Equals(&other.c) || !sig.KeyImage.Equals(&other.KeyImage) || !sig.D.Equals(&other.D) {
+		return false
+	}
+
+	if len(sig.s) != len(other.s) {
+		return false
+	}
+	for i := range sig.s {
+		if !sig.s[i].Equals(&other.s[i]) {
+			return false
+		}
+	}
+
+	if !includeKeys {
+		return true
+	}
+
+	if len(sig.Members) != len(other.Members) {
+		return false
+	}
+	for i := range sig.Members {
+		if !sig.Members[i].Equals(other.Members[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
+
+func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	return nil
+}
@@ -0,0 +1,262 @@
+package clsag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/mlsag"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+func TestCLSAGProveVerify(t *testing.T) {
+	for _, numMembers := range []int{1, 2, 11, 32} {
+		proof := generateRandProof(numMembers)
+
+		sig, keyImage, err := proof.Prove()
+		require.Nil(t, err)
+		assert.Equal(t, numMembers, len(sig.Members))
+		assert.Equal(t, numMembers, len(sig.s))
+
+		ok, err := sig.Verify(keyImage)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestCLSAGKeyImage(t *testing.T) {
+	proof := generateRandProof(5)
+
+	sig, keyImage, err := proof.Prove()
+	require.Nil(t, err)
+
+	// The key image has the same format as the MLSAG key images
+	expected := mlsag.CalculateKeyImage(proof.primaryKey, privKeyToPubKey(proof.primaryKey))
+	assert.True(t, expected.Equals(&keyImage))
+	assert.True(t, expected.Equals(&sig.KeyImage))
+}
+
+func TestCLSAGBadSig(t *testing.T) {
+
+	tamper := map[string]func(sig *Signature, keyImage *ristretto.Point){
+		"message": func(sig *Signature, keyImage *ristretto.Point) {
+			sig.Msg = []byte("something random")
+		},
+		"key image": func(sig *Signature, keyImage *ristretto.Point) {
+			keyImage.Rand()
+		},
+		"aux key image": func(sig *Signature, keyImage *ristretto.Point) {
+			sig.D.Rand()
+		},
+		"commitment": func(sig *Signature, keyImage *ristretto.Point) {
+			sig.Members[0].Commitment.Rand()
+		},
+		"response": func(sig *Signature, keyImage *ristretto.Point) {
+			sig.s[1].Rand()
+		},
+		"missing response": func(sig *Signature, keyImage *ristretto.Point) {
+			sig.s = sig.s[1:]
+		},
+		"identity key image": func(sig *Signature, keyImage *ristretto.Point) {
+			keyImage.SetZero()
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			proof := generateRandProof(8)
+			sig, keyImage, err := proof.Prove()
+			require.Nil(t, err)
+
+			fn(sig, &keyImage)
+
+			ok, err := sig.Verify(keyImage)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCLSAGCommitmentToZero(t *testing.T) {
+	ped := pedersen.New([]byte("vosbor.BulletProof.v1"))
+
+	var amount ristretto.Scalar
+	amount.SetOne()
+
+	// Commitment of the output being spent and the pseudo output
+	// commitment to the same amount with a different blinder
+	commitment := ped.CommitToScalar(amount)
+	pseudoOut := ped.CommitToScalar(amount)
+
+	var primaryKey ristretto.Scalar
+	primaryKey.Rand()
+
+	proof := NewProof()
+	proof.AddDecoys(generateDecoys(10))
+	proof.SubCommToZero(pseudoOut.Commit)
+
+	pubKey := proof.SetPrimaryKey(primaryKey)
+	commToZero := pedersen.Sub(commitment, pseudoOut)
+	proof.SetCommToZero(commToZero.BlindingFactor)
+	proof.SetMsg([]byte("hello world"))
+
+	sig, keyImage, err := proof.Prove()
+	require.Nil(t, err)
+
+	ok, err := sig.Verify(keyImage)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// The signers commitment in the ring is the commitment minus the pseudo output
+	found := false
+	for _, m := range sig.Members {
+		if m.PubKey.Equals(&pubKey) {
+			assert.True(t, m.Commitment.Equals(&commToZero.Commit))
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	// Swapping in a commitment which does not open to zero breaks the signature
+	for i := range sig.Members {
+		if sig.Members[i].PubKey.Equals(&pubKey) {
+			sig.Members[i].Commitment = commitment.Commit
+		}
+	}
+	ok, err = sig.Verify(keyImage)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestCLSAGZeroKeys(t *testing.T) {
+	proof := NewProof()
+	proof.AddDecoys(generateDecoys(4))
+
+	_, _, err := proof.Prove()
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, includeKeys := range []bool{false, true} {
+		proof := generateRandProof(16)
+		sig, keyImage, err := proof.Prove()
+		require.Nil(t, err)
+
+		buf := &bytes.Buffer{}
+		err = sig.Encode(buf, includeKeys)
+		require.Nil(t, err)
+
+		decodedSig := &Signature{}
+		err = decodedSig.Decode(buf, includeKeys)
+		require.Nil(t, err)
+		assert.Equal(t, 0, buf.Len())
+
+		assert.True(t, sig.Equals(*decodedSig, includeKeys))
+		assert.True(t, keyImage.Equals(&decodedSig.KeyImage))
+
+		if includeKeys {
+			decodedSig.Msg = sig.Msg
+			ok, err := decodedSig.Verify(decodedSig.KeyImage)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+		}
+	}
+}
+
+func TestDecodeHostileRingSize(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	var s ristretto.Scalar
+	var p ristretto.Point
+	p.Rand()
+	buf.Write(s.Bytes())
+	buf.Write(p.Bytes())
+	buf.Write(p.Bytes())
+	binary.Write(buf, binary.BigEndian, uint32(0xFFFFFFFF))
+
+	err := (&Signature{}).Decode(buf, true)
+	assert.NotNil(t, err)
+}
+
+func TestSizeAgainstMLSAG(t *testing.T) {
+	numMembers := 11
+
+	proof := generateRandProof(numMembers)
+	sig, _, err := proof.Prove()
+	require.Nil(t, err)
+
+	clsagBuf := &bytes.Buffer{}
+	err = sig.Encode(clsagBuf, false)
+	require.Nil(t, err)
+
+	dk := mlsag.NewDualKey()
+	for i := 0; i < numMembers-1; i++ {
+		var keys mlsag.PubKeys
+		for j := 0; j < 2; j++ {
+			var p ristretto.Point
+			p.Rand()
+			keys.AddPubKey(p)
+		}
+		dk.AddDecoy(keys)
+	}
+	var primaryKey, commToZero ristretto.Scalar
+	primaryKey.Rand()
+	commToZero.Rand()
+	dk.SetPrimaryKey(primaryKey)
+	dk.SetCommToZero(commToZero)
+	dk.SetMsg([]byte("hello world"))
+
+	mlsagSig, _, err := dk.Prove()
+	require.Nil(t, err)
+
+	mlsagBuf := &bytes.Buffer{}
+	err = mlsagSig.EncodeVersioned(mlsagBuf, false, true)
+	require.Nil(t, err)
+
+	assert.True(t, clsagBuf.Len()*10 < mlsagBuf.Len()*6)
+}
+
+func BenchmarkProve(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		proof := generateRandProof(11)
+		proof.Prove()
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	proof := generateRandProof(11)
+	sig, keyImage, _ := proof.Prove()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sig.Verify(keyImage)
+	}
+}
+
+func generateDecoys(n int) []Member {
+	var members []Member
+	for i := 0; i < n; i++ {
+		var m Member
+		m.PubKey.Rand()
+		m.Commitment.Rand()
+		members = append(members, m)
+	}
+	return members
+}
+
+func generateRandProof(numMembers int) *Proof {
+	proof := NewProof()
+	proof.AddDecoys(generateDecoys(numMembers - 1))
+
+	var primaryKey, commToZero ristretto.Scalar
+	primaryKey.Rand()
+	commToZero.Rand()
+	proof.SetPrimaryKey(primaryKey)
+	proof.SetCommToZero(commToZero)
+
+	proof.SetMsg([]byte("hello world"))
+	return proof
+}
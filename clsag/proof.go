@@ -0,0 +1,145 @@
+package clsag
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Member is a single ring member, made of an output key
+// and the commitment attached to it
+type Member struct {
+	PubKey     ristretto.Point
+	Commitment ristretto.Point
+}
+
+// Equals returns true if both members hold the same points
+func (m Member) Equals(other Member) bool {
+	return m.PubKey.Equals(&other.PubKey) && m.Commitment.Equals(&other.Commitment)
+}
+
+// Proof collects the secrets of the signer and the decoys
+// which form the ring of a CLSAG signature
+type Proof struct {
+	// index of the signer in the ring
+	index int
+
+	// primaryKey is the private key of the signers output key
+	primaryKey ristretto.Scalar
+
+	// commToZero is the private key of the signers commitment to zero
+	commToZero ristretto.Scalar
+
+	// All members of the ring including the signer
+	members []Member
+
+	// message to be signed
+	msg []byte
+}
+
+// NewProof returns an empty CLSAG proof
+func NewProof() *Proof {
+	return &Proof{}
+}
+
+// SetPrimaryKey sets the private key of the output being spent
+// and returns the corresponding public key
+func (p *Proof) SetPrimaryKey(key ristretto.Scalar) ristretto.Point {
+	p.primaryKey = key
+	return privKeyToPubKey(key)
+}
+
+// SetCommToZero sets the private key of the signers commitment to zero
+// and returns the corresponding public key
+func (p *Proof) SetCommToZero(key ristretto.Scalar) ristretto.Point {
+	p.commToZero = key
+	return privKeyToPubKey(key)
+}
+
+// SetMsg sets the message to be signed
+func (p *Proof) SetMsg(msg []byte) {
+	p.msg = msg
+}
+
+// AddDecoy adds a decoy member to the ring
+func (p *Proof) AddDecoy(m Member) {
+	p.members = append(p.members, m)
+}
+
+// AddDecoys adds a set of decoy members to the ring
+func (p *Proof) AddDecoys(members []Member) {
+	for _, m := range members {
+		p.AddDecoy(m)
+	}
+}
+
+// SubCommToZero subtracts c from the commitment of every decoy in the ring
+func (p *Proof) SubCommToZero(c ristretto.Point) {
+	for i := range p.members {
+		commitment := &p.members[i].Commitment
+		commitment.Sub(commitment, &c)
+	}
+}
+
+// LenMembers returns the number of decoys added to the ring
+func (p *Proof) LenMembers() int {
+	return len(p.members)
+}
+
+// Prove creates the CLSAG signature and returns it with the key image of the primary key
+func (p *Proof) Prove() (*Signature, ristretto.Point, error) {
+
+	if (p.primaryKey.IsNonZeroI() == 0) || (p.commToZero.IsNonZeroI() == 0) {
+		return nil, ristretto.Point{}, errors.New("primary key or commitment to zero cannot be zero")
+	}
+
+	p.addSigner()
+
+	err := p.shuffleSet()
+	if err != nil {
+		return nil, ristretto.Point{}, err
+	}
+
+	sig, err := p.prove()
+	if err != nil {
+		return nil, ristretto.Point{}, err
+	}
+	return sig, sig.KeyImage, nil
+}
+
+func (p *Proof) addSigner() {
+	p.index = len(p.members)
+	p.members = append(p.members, Member{
+		PubKey:     privKeyToPubKey(p.primaryKey),
+		Commitment: privKeyToPubKey(p.commToZero),
+	})
+}
+
+// shuffle all members and update the index of the signer
+func (p *Proof) shuffleSet() error {
+	for i := len(p.members) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+		j := int(n.Int64())
+
+		p.members[i], p.members[j] = p.members[j], p.members[i]
+
+		switch p.index {
+		case i:
+			p.index = j
+		case j:
+			p.index = i
+		}
+	}
+	return nil
+}
+
+func privKeyToPubKey(privkey ristretto.Scalar) ristretto.Point {
+	var pubkey ristretto.Point
+	pubkey.ScalarMultBase(&privkey)
+	return pubkey
+}
@@ -0,0 +1,54 @@
+package clsag
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDecoys(t *testing.T) {
+	p := NewProof()
+	assert.Equal(t, 0, p.LenMembers())
+
+	for i := 0; i < 100; i++ {
+		p.AddDecoy(generateDecoys(1)[0])
+		assert.Equal(t, i+1, p.LenMembers())
+	}
+}
+
+func TestSubCommToZero(t *testing.T) {
+	p := NewProof()
+	decoys := generateDecoys(20)
+	p.AddDecoys(decoys)
+
+	var x ristretto.Point
+	x.Rand()
+	p.SubCommToZero(x)
+
+	for i := range p.members {
+		var expected ristretto.Point
+		expected.Sub(&decoys[i].Commitment, &x)
+
+		assert.True(t, expected.Equals(&p.members[i].Commitment))
+		assert.True(t, decoys[i].PubKey.Equals(&p.members[i].PubKey))
+	}
+}
+
+func TestShuffleSet(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		p := generateRandProof(10)
+		p.addSigner()
+
+		err := p.shuffleSet()
+		require.Nil(t, err)
+
+		signer := p.members[p.index]
+		expected := privKeyToPubKey(p.primaryKey)
+		assert.True(t, expected.Equals(&signer.PubKey))
+
+		expected = privKeyToPubKey(p.commToZero)
+		assert.True(t, expected.Equals(&signer.Commitment))
+	}
+}
@@ -0,0 +1,93 @@
+package mlsag
+
+import (
+	"bytes"
+	"fmt"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+)
+
+// SignatureV2 signatures derive their challenges from a transcript which
+// absorbs the message, the complete pubkey matrix and the key images
+// up front, binding every challenge in the ring to the full context
+const SignatureV2 uint8 = 2
+
+var transcriptLabel = []byte("vosbor.mlsag")
+
+// challengeContext derives the ring challenges for a signature version
+type challengeContext struct {
+	version uint8
+	msg     []byte
+
+	// transcript is the state after absorbing the context of a v2 signature
+	transcript *fiatshamir.Transcript
+}
+
+func newChallengeContext(version uint8, msg []byte, pubKeysMatrix []PubKeys, keyImages []ristretto.Point, linkable []bool) (*challengeContext, error) {
+
+	ctx := &challengeContext{
+		version: version,
+		msg:     msg,
+	}
+
+	switch version {
+	case SignatureV1:
+		return ctx, nil
+	case SignatureV2:
+	default:
+		return nil, fmt.Errorf("unsupported signature version %d", version)
+	}
+
+	t := fiatshamir.NewTranscript(transcriptLabel)
+	t.AppendUint64([]byte("version"), uint64(version))
+	t.AppendMessage([]byte("msg"), msg)
+
+	t.AppendUint64([]byte("members"), uint64(len(pubKeysMatrix)))
+	for i := range pubKeysMatrix {
+		t.AppendUint64([]byte("keys"), uint64(pubKeysMatrix[i].Len()))
+		for j := range pubKeysMatrix[i].keys {
+			t.AppendPoint([]byte("pubkey"), pubKeysMatrix[i].keys[j])
+		}
+	}
+
+	t.AppendUint64([]byte("key-images"), uint64(len(keyImages)))
+	for i := range keyImages {
+		t.AppendPoint([]byte("key-image"), keyImages[i])
+	}
+
+	// Signatures without a mask link the leading columns
+	if linkable != nil {
+		if len(linkable) > MaxKeys {
+			return nil, fmt.Errorf("linkable mask covers %d columns, the maximum is %d", len(linkable), MaxKeys)
+		}
+		t.AppendUint64([]byte("linkable"), uint64(encodeMask(linkable)))
+	}
+
+	ctx.transcript = t
+	return ctx, nil
+}
+
+// derive computes the challenge for the member following index, from
+// the points computed with the responses and challenge of index
+func (ctx *challengeContext) derive(index int, points []ristretto.Point) ristretto.Scalar {
+
+	var challenge ristretto.Scalar
+
+	if ctx.version == SignatureV1 {
+		buf := &bytes.Buffer{}
+		buf.Write(ctx.msg)
+		for i := range points {
+			buf.Write(points[i].Bytes())
+		}
+		challenge.Derive(buf.Bytes())
+		return challenge
+	}
+
+	t := ctx.transcript.Clone()
+	t.AppendUint64([]byte("index"), uint64(index))
+	for i := range points {
+		t.AppendPoint([]byte("commitment"), points[i])
+	}
+	return t.ChallengeScalar([]byte("challenge"))
+}
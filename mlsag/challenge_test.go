@@ -0,0 +1,98 @@
+package mlsag
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMLSAGV2ProveVerify(t *testing.T) {
+	proof := generateRandProof(10, 3)
+	proof.SetVersion(SignatureV2)
+
+	sig, keyImages, err := proof.prove(true)
+	require.Nil(t, err)
+	assert.Equal(t, SignatureV2, sig.Version())
+
+	ok, err := sig.Verify(keyImages)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// The same signature must not verify under the v1 challenge
+	sig.version = SignatureV1
+	ok, err = sig.Verify(keyImages)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestMLSAGV2BindsContext(t *testing.T) {
+
+	tamper := map[string]func(sig *Signature, keyImages []ristretto.Point){
+		"message": func(sig *Signature, keyImages []ristretto.Point) {
+			sig.Msg = []byte("something random")
+		},
+		"key image": func(sig *Signature, keyImages []ristretto.Point) {
+			keyImages[0].Rand()
+		},
+		"ring member": func(sig *Signature, keyImages []ristretto.Point) {
+			sig.PubKeys[0].keys[0].Rand()
+		},
+		"ring order": func(sig *Signature, keyImages []ristretto.Point) {
+			sig.PubKeys[0], sig.PubKeys[1] = sig.PubKeys[1], sig.PubKeys[0]
+			sig.r[0], sig.r[1] = sig.r[1], sig.r[0]
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			proof := generateRandProof(6, 2)
+			proof.SetVersion(SignatureV2)
+
+			sig, keyImages, err := proof.prove(false)
+			require.Nil(t, err)
+
+			fn(sig, keyImages)
+
+			ok, err := sig.Verify(keyImages)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMLSAGV2Encoding(t *testing.T) {
+	dk := generateRandDualKeyProof(11)
+	dk.SetVersion(SignatureV2)
+
+	sig, keyImage, err := dk.Prove()
+	require.Nil(t, err)
+
+	// The legacy encoding cannot carry the version
+	err = sig.Encode(&bytes.Buffer{}, true)
+	assert.NotNil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.EncodeVersioned(buf, true, true)
+	require.Nil(t, err)
+
+	decodedSig := &Signature{}
+	err = decodedSig.DecodeVersioned(buf)
+	require.Nil(t, err)
+	assert.Equal(t, SignatureV2, decodedSig.Version())
+
+	decodedSig.Msg = sig.Msg
+	ok, err := decodedSig.Verify([]ristretto.Point{keyImage})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	proof := generateRandProof(4, 2)
+	proof.SetVersion(SignatureV2 + 1)
+
+	_, _, err := proof.prove(true)
+	assert.NotNil(t, err)
+}
@@ -30,10 +30,6 @@ func (d *DualKey) SetCommToZero(key ristretto.Scalar) ristretto.Point {
 	return privKeyToPubKey(key)
 }
 
-func (d *DualKey) SetMsg(msg []byte) {
-	d.Proof.msg = msg
-}
-
 // SubCommToZero subtracts p from every point from the second public key
 // in the matrix of decoy pubkeys
 func (d *DualKey) SubCommToZero(p ristretto.Point) {
@@ -50,9 +46,9 @@ func (d *DualKey) Prove() (*Signature, ristretto.Point, error) {
 	}
 
 	d.AddSecret(d.dualkeys[0])
-	d.AddSecret(d.dualkeys[1])
+	d.AddUnlinkableSecret(d.dualkeys[1])
 
-	sig, keyimage, err := d.prove(true)
+	sig, keyimage, err := d.prove(false)
 	if err != nil {
 		return nil, ristretto.Point{}, err
 	}
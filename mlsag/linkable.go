@@ -0,0 +1,93 @@
+package mlsag
+
+import (
+	"errors"
+	"fmt"
+)
+
+// columnsFromMask returns the indices of the linkable columns of a signature
+// with numKeys keys per member. A nil mask marks the first numKeyImages
+// columns as linkable, which is the layout of signatures without a mask
+func columnsFromMask(mask []bool, numKeys, numKeyImages int) ([]int, error) {
+
+	if mask == nil {
+		if numKeyImages > numKeys {
+			return nil, errors.New("there cannot be more key images than keys per member")
+		}
+		columns := make([]int, numKeyImages)
+		for i := range columns {
+			columns[i] = i
+		}
+		return columns, nil
+	}
+
+	if len(mask) != numKeys {
+		return nil, fmt.Errorf("linkable mask covers %d columns, expected %d", len(mask), numKeys)
+	}
+
+	var columns []int
+	for i := range mask {
+		if mask[i] {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) != numKeyImages {
+		return nil, fmt.Errorf("linkable mask expects %d key images, got %d", len(columns), numKeyImages)
+	}
+	return columns, nil
+}
+
+// maskFromColumns returns the linkable mask for numKeys columns, or nil
+// if the linkable columns are the leading columns, as the mask is implied
+func maskFromColumns(columns []int, numKeys int) []bool {
+	implied := true
+	for i := range columns {
+		if columns[i] != i {
+			implied = false
+		}
+	}
+	if implied {
+		return nil
+	}
+
+	mask := make([]bool, numKeys)
+	for _, c := range columns {
+		mask[c] = true
+	}
+	return mask
+}
+
+// normalizeMask returns nil if the mask only marks the leading columns
+func normalizeMask(mask []bool) []bool {
+	var columns []int
+	for i := range mask {
+		if mask[i] {
+			columns = append(columns, i)
+		}
+	}
+	return maskFromColumns(columns, len(mask))
+}
+
+// encodeMask packs the mask into a bitmask, column i being bit i
+func encodeMask(mask []bool) uint32 {
+	var bits uint32
+	for i := range mask {
+		if mask[i] {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits
+}
+
+// decodeMask unpacks a bitmask for numKeys columns
+func decodeMask(bits uint32, numKeys uint32) ([]bool, error) {
+	if numKeys < 32 && bits>>numKeys != 0 {
+		return nil, errors.New("linkable mask marks columns beyond the number of keys")
+	}
+
+	mask := make([]bool, numKeys)
+	for i := range mask {
+		mask[i] = bits&(1<<uint(i)) != 0
+	}
+	return normalizeMask(mask), nil
+}
@@ -0,0 +1,209 @@
+package mlsag
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkableColumns(t *testing.T) {
+	for _, version := range []uint8{SignatureV1, SignatureV2} {
+		proof, privKeys := generateMaskedProof(8, []bool{false, true, false, true})
+		proof.SetVersion(version)
+
+		sig, keyImages, err := proof.Prove()
+		require.Nil(t, err)
+		assert.Equal(t, []bool{false, true, false, true}, sig.Linkable)
+
+		// Key images are produced for the linkable columns only, in column order
+		require.Equal(t, 2, len(keyImages))
+		assert.Equal(t, CalculateKeyImage(privKeys[1], privKeyToPubKey(privKeys[1])), keyImages[0])
+		assert.Equal(t, CalculateKeyImage(privKeys[3], privKeyToPubKey(privKeys[3])), keyImages[1])
+
+		ok, err := sig.Verify(keyImages)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestLinkableLeadingColumns(t *testing.T) {
+	proof, _ := generateMaskedProof(6, []bool{true, true, false})
+
+	sig, keyImages, err := proof.Prove()
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(keyImages))
+
+	// The mask is implied by the number of key images, so that
+	// the signature keeps the legacy layout
+	assert.Nil(t, sig.Linkable)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf, true)
+	require.Nil(t, err)
+
+	decodedSig := &Signature{}
+	err = decodedSig.Decode(buf, true)
+	require.Nil(t, err)
+	decodedSig.Msg = sig.Msg
+
+	ok, err := decodedSig.Verify(keyImages)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDualKeyLinkable(t *testing.T) {
+	dk := generateRandDualKeyProof(5)
+
+	sig, keyImage, err := dk.Prove()
+	require.Nil(t, err)
+	assert.Nil(t, sig.Linkable)
+
+	expected := CalculateKeyImage(dk.dualkeys[0], privKeyToPubKey(dk.dualkeys[0]))
+	assert.True(t, expected.Equals(&keyImage))
+}
+
+func TestLinkableBadMask(t *testing.T) {
+
+	tamper := map[string]func(sig *Signature, keyImages *[]ristretto.Point){
+		"other columns": func(sig *Signature, keyImages *[]ristretto.Point) {
+			sig.Linkable = []bool{true, false, true}
+		},
+		"no mask": func(sig *Signature, keyImages *[]ristretto.Point) {
+			sig.Linkable = nil
+		},
+		"short mask": func(sig *Signature, keyImages *[]ristretto.Point) {
+			sig.Linkable = sig.Linkable[:2]
+		},
+		"missing key image": func(sig *Signature, keyImages *[]ristretto.Point) {
+			*keyImages = (*keyImages)[:1]
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			proof, _ := generateMaskedProof(5, []bool{false, true, true})
+
+			sig, keyImages, err := proof.Prove()
+			require.Nil(t, err)
+
+			fn(sig, &keyImages)
+
+			ok, err := sig.Verify(keyImages)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestLinkableNoColumns(t *testing.T) {
+	proof, _ := generateMaskedProof(5, []bool{false, false})
+
+	_, _, err := proof.Prove()
+	assert.NotNil(t, err)
+
+	_, _, err = (&Proof{}).Prove()
+	assert.NotNil(t, err)
+}
+
+func TestLinkableTooManyKeys(t *testing.T) {
+
+	// column 32 would not fit in the mask bound to the challenges
+	mask := make([]bool, 33)
+	mask[0] = true
+	mask[32] = true
+
+	proof, _ := generateMaskedProof(2, mask)
+	proof.SetVersion(SignatureV2)
+	_, _, err := proof.Prove()
+	assert.NotNil(t, err)
+
+	proof, _ = generateMaskedProof(2, make([]bool, MaxKeys+1))
+	_, _, err = proof.Prove()
+	assert.NotNil(t, err)
+
+	_, err = newChallengeContext(SignatureV2, nil, nil, nil, mask)
+	assert.NotNil(t, err)
+}
+
+func TestLinkableEncoding(t *testing.T) {
+	proof, _ := generateMaskedProof(5, []bool{false, true, false})
+	proof.SetVersion(SignatureV2)
+
+	sig, _, err := proof.Prove()
+	require.Nil(t, err)
+
+	// The legacy encoding cannot carry the mask
+	err = sig.Encode(&bytes.Buffer{}, true)
+	assert.NotNil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = WriteSignature(buf, sig, true, true)
+	require.Nil(t, err)
+
+	decodedSig, err := ReadSignature(buf)
+	require.Nil(t, err)
+	assert.Equal(t, sig.Linkable, decodedSig.Linkable)
+	assert.True(t, sig.Equals(*decodedSig, true))
+
+	decodedSig.Msg = sig.Msg
+	ok, err := decodedSig.Verify(decodedSig.KeyImages)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestEncodeKeyImagesMatchMask(t *testing.T) {
+	proof, _ := generateMaskedProof(3, []bool{false, true, true})
+
+	sig, _, err := proof.Prove()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(sig.KeyImages))
+
+	// the mask has two linkable columns
+	tampered := *sig
+	tampered.KeyImages = sig.KeyImages[:1]
+	assert.NotNil(t, tampered.EncodeVersioned(&bytes.Buffer{}, true, true))
+	assert.Nil(t, tampered.EncodeVersioned(&bytes.Buffer{}, true, false))
+
+	tampered.KeyImages = append(sig.KeyImages[:1:1], sig.KeyImages...)
+	assert.NotNil(t, tampered.EncodeVersioned(&bytes.Buffer{}, true, true))
+}
+
+func TestDecodeMalformedMask(t *testing.T) {
+	proof, _ := generateMaskedProof(3, []bool{false, true})
+
+	sig, _, err := proof.Prove()
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.EncodeVersioned(buf, false, false)
+	require.Nil(t, err)
+
+	// Mark a third column, which the members do not have
+	encoded := buf.Bytes()
+	encoded[len(encoded)-1] |= 1 << 2
+
+	err = (&Signature{}).DecodeVersioned(bytes.NewReader(encoded))
+	assert.NotNil(t, err)
+}
+
+// generateMaskedProof returns a proof with one key per entry of the mask,
+// the key being linkable if the entry is set
+func generateMaskedProof(numUsers int, mask []bool) (*Proof, PrivKeys) {
+	proof := &Proof{}
+	proof.AddDecoys(generateDecoys(numUsers-1, len(mask)))
+
+	privKeys := generatePrivKeys(len(mask))
+	for i := range privKeys {
+		if mask[i] {
+			proof.AddSecret(privKeys[i])
+		} else {
+			proof.AddUnlinkableSecret(privKeys[i])
+		}
+	}
+
+	proof.SetMsg([]byte("hello world"))
+	return proof, privKeys
+}
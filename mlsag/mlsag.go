@@ -1,7 +1,6 @@
 package mlsag
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -15,27 +14,54 @@ type Signature struct {
 	r       []Responses
 	PubKeys []PubKeys
 	Msg     []byte
+
+	// KeyImages are the key images produced alongside the signature.
+	// They are only transported by the versioned encoding
+	KeyImages []ristretto.Point
+
+	// Linkable marks the columns of the pubkey matrix which have a key image.
+	// A nil mask means the first len(keyImages) columns are linkable
+	Linkable []bool
+
+	// version of the challenge derivation used by the signature
+	version uint8
+}
+
+// Version returns the version of the signature. Signatures decoded from the
+// legacy encoding are always SignatureV1
+func (s *Signature) Version() uint8 {
+	if s.version == 0 {
+		return SignatureV1
+	}
+	return s.version
 }
 
 func (s *Signature) Encode(w io.Writer, encodeKeys bool) error {
-	err := binary.Write(w, binary.BigEndian, s.c.Bytes())
+	if s.Version() != SignatureV1 {
+		return errors.New("only v1 signatures can use the legacy encoding, use EncodeVersioned")
+	}
+	if s.Linkable != nil {
+		return errors.New("signatures with a linkable mask cannot use the legacy encoding, use EncodeVersioned")
+	}
+
+	// lenR is the number of response vectors == num users = num pubkey vectors
+	// numResponses is the number of responses per user  == num pubkeys
+	lenR, numResponses, err := s.checkEncodable(encodeKeys)
 	if err != nil {
 		return err
 	}
 
-	// lenR is the number of response vectors == num users = num pubkey vectors
-	lenR := uint32(len(s.r))
-	err = binary.Write(w, binary.BigEndian, lenR)
+	err = binary.Write(w, binary.BigEndian, s.c.Bytes())
 	if err != nil {
 		return err
 	}
 
-	if lenR <= 0 {
-		return nil
+	err = binary.Write(w, binary.BigEndian, lenR)
+	if err != nil {
+		return err
 	}
 
-	// numResponses is the number of responses per user  == num pubkeys
-	numResponses := uint32(s.r[0].Len())
+	// numResponses is written even without responses, as Decode expects it
 	err = binary.Write(w, binary.BigEndian, numResponses)
 	if err != nil {
 		return err
@@ -69,6 +95,8 @@ func (s *Signature) Decode(r io.Reader, decodeKeys bool) error {
 	if s == nil {
 		return errors.New("struct is nil")
 	}
+	s.version = SignatureV1
+	s.Linkable = nil
 
 	err := readerToScalar(r, &s.c)
 	if err != nil {
@@ -85,6 +113,11 @@ func (s *Signature) Decode(r io.Reader, decodeKeys bool) error {
 		return err
 	}
 
+	err = checkBounds(lenR, numResponses)
+	if err != nil {
+		return err
+	}
+
 	// Decode the responses
 	s.r = make([]Responses, lenR)
 	for i := uint32(0); i < lenR; i++ {
@@ -115,6 +148,16 @@ func (s Signature) Equals(other Signature, includeKeys bool) bool {
 		return ok
 	}
 
+	if len(s.r) != len(other.r) || len(s.Linkable) != len(other.Linkable) {
+		return false
+	}
+
+	for i := range s.Linkable {
+		if s.Linkable[i] != other.Linkable[i] {
+			return false
+		}
+	}
+
 	for i := range s.r {
 		ok = s.r[i].Equals(other.r[i])
 		if !ok {
@@ -141,6 +184,12 @@ func (s Signature) Equals(other Signature, includeKeys bool) bool {
 
 func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point, error) {
 
+	// the linkable columns are bound to the challenges as a 32 bit mask,
+	// and signatures with more keys cannot be decoded
+	if proof.privKeys.Len() > MaxKeys {
+		return nil, nil, fmt.Errorf("number of keys %d exceeds the maximum of %d", proof.privKeys.Len(), MaxKeys)
+	}
+
 	proof.addSignerPubKey()
 
 	// Shuffle the PubKeys and update the index for our corresponding key
@@ -157,6 +206,12 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 		}
 	}
 
+	columns := proof.linkableColumns(skipLastKeyImage)
+	if len(columns) == 0 {
+		return nil, nil, errors.New("at least one column must be linkable")
+	}
+	linkable := maskFromColumns(columns, pubKeyVecLen)
+
 	keyImages := proof.calculateKeyImages(skipLastKeyImage)
 	nonces := generateNonces(len(proof.privKeys))
 
@@ -169,10 +224,14 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 	// Let secretIndex = index of signer
 	secretIndex := proof.index
 
+	ctx, err := newChallengeContext(proof.Version(), proof.msg, proof.pubKeysMatrix, keyImages, linkable)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Generate C_{secretIndex+1}
-	buf := &bytes.Buffer{}
-	buf.Write(proof.msg)
 	signersPubKeys := proof.pubKeysMatrix[secretIndex]
+	points := make([]ristretto.Point, 0, len(nonces)+len(keyImages))
 
 	for i := 0; i < len(nonces); i++ {
 
@@ -181,28 +240,21 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 		// P = nonce * G
 		var P ristretto.Point
 		P.ScalarMultBase(&nonce)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return nil, nil, err
-		}
+		points = append(points, P)
 	}
 
-	for i := 0; i < len(keyImages); i++ {
+	for _, column := range columns {
 
-		nonce := nonces[i]
+		nonce := nonces[column]
 
 		// P = nonce * H(K)
 		var P, hK ristretto.Point
-		hK.Derive(signersPubKeys.keys[i].Bytes())
+		hK.Derive(signersPubKeys.keys[column].Bytes())
 		P.ScalarMult(&hK, &nonce)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return nil, nil, err
-		}
+		points = append(points, P)
 	}
 
-	var CjPlusOne ristretto.Scalar
-	CjPlusOne.Derive(buf.Bytes())
+	CjPlusOne := ctx.derive(secretIndex, points)
 
 	// generate challenges
 	challenges := make([]ristretto.Scalar, numUsers)
@@ -221,7 +273,7 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 		fakeResponses := responses[prevIndex]
 		decoyPubKeys := proof.pubKeysMatrix[prevIndex]
 
-		c, err := generateChallenge(proof.msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
+		c, err := generateChallenge(ctx, prevIndex, fakeResponses, keyImages, columns, decoyPubKeys, prevChallenge)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -249,10 +301,13 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 	responses[proof.index] = realResponse
 
 	sig := &Signature{
-		c:       challenges[0],
-		r:       responses,
-		PubKeys: proof.pubKeysMatrix,
-		Msg:     proof.msg,
+		c:         challenges[0],
+		r:         responses,
+		PubKeys:   proof.pubKeysMatrix,
+		Msg:       proof.msg,
+		KeyImages: keyImages,
+		Linkable:  linkable,
+		version:   proof.Version(),
 	}
 
 	return sig, keyImages, nil
@@ -264,6 +319,20 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 		return false, errors.New("cannot have zero length for responses, pubkeys or key images")
 	}
 
+	if len(sig.PubKeys) != len(sig.r) {
+		return false, errors.New("number of pubkey vectors does not match the number of response vectors")
+	}
+
+	columns, err := columnsFromMask(sig.Linkable, sig.PubKeys[0].Len(), len(keyImages))
+	if err != nil {
+		return false, err
+	}
+
+	ctx, err := newChallengeContext(sig.Version(), sig.Msg, sig.PubKeys, keyImages, sig.Linkable)
+	if err != nil {
+		return false, err
+	}
+
 	numUsers := len(sig.r)
 	index := 0
 
@@ -278,7 +347,7 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 
 		fakeResponses := sig.r[prevIndex]
 		decoyPubKeys := sig.PubKeys[prevIndex]
-		challenge, err := generateChallenge(sig.Msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
+		challenge, err := generateChallenge(ctx, prevIndex, fakeResponses, keyImages, columns, decoyPubKeys, prevChallenge)
 		if err != nil {
 			return false, err
 		}
@@ -293,7 +362,7 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 	fakeResponses := sig.r[prevIndex]
 	decoyPubKeys := sig.PubKeys[prevIndex]
 
-	challenge, err := generateChallenge(sig.Msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
+	challenge, err := generateChallenge(ctx, prevIndex, fakeResponses, keyImages, columns, decoyPubKeys, prevChallenge)
 	if err != nil {
 		return false, err
 	}
@@ -338,18 +407,25 @@ func generateResponses(m int, n, skip int) []Responses {
 }
 
 func generateChallenge(
-	msg []byte,
+	ctx *challengeContext,
+	index int,
 	respsonses Responses,
 	keyImages []ristretto.Point,
+	columns []int,
 	pubKeys PubKeys,
 	prevChallenge ristretto.Scalar) (ristretto.Scalar, error) {
 
-	buf := &bytes.Buffer{}
-	_, err := buf.Write(msg)
-	if err != nil {
-		return ristretto.Scalar{}, err
+	if respsonses.Len() != pubKeys.Len() || len(keyImages) != len(columns) {
+		return ristretto.Scalar{}, errors.New("number of responses, pubkeys and key images do not match")
+	}
+	for _, column := range columns {
+		if column < 0 || column >= pubKeys.Len() {
+			return ristretto.Scalar{}, errors.New("linkable column is out of range")
+		}
 	}
 
+	points := make([]ristretto.Point, 0, pubKeys.Len()+len(keyImages))
+
 	for i := 0; i < pubKeys.Len(); i++ {
 
 		r := respsonses[i]
@@ -359,33 +435,23 @@ func generateChallenge(
 		P.ScalarMultBase(&r)
 		cK.ScalarMult(&pubKeys.keys[i], &prevChallenge)
 		P.Add(&P, &cK)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return ristretto.Scalar{}, err
-		}
-
+		points = append(points, P)
 	}
 
-	for i := 0; i < len(keyImages); i++ {
-		r := respsonses[i]
+	for i, column := range columns {
+		r := respsonses[column]
 
 		// P = r * H(K) + c * Ki
 		var P, cK ristretto.Point
 		var hK ristretto.Point
-		hK.Derive(pubKeys.keys[i].Bytes())
+		hK.Derive(pubKeys.keys[column].Bytes())
 		P.ScalarMult(&hK, &r)
 		cK.ScalarMult(&keyImages[i], &prevChallenge)
 		P.Add(&P, &cK)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return ristretto.Scalar{}, err
-		}
+		points = append(points, P)
 	}
 
-	var challenge ristretto.Scalar
-	challenge.Derive(buf.Bytes())
-
-	return challenge, nil
+	return ctx.derive(index, points), nil
 }
 
 func (proof *Proof) calculateKeyImages(skipLastKeyImage bool) []ristretto.Point {
@@ -394,17 +460,9 @@ func (proof *Proof) calculateKeyImages(skipLastKeyImage bool) []ristretto.Point
 	privKeys := proof.privKeys
 	pubKeys := proof.signerPubKeys
 
-	for i := 0; i < len(privKeys); i++ {
+	for _, i := range proof.linkableColumns(skipLastKeyImage) {
 		keyImages = append(keyImages, CalculateKeyImage(privKeys[i], pubKeys.keys[i]))
 	}
-
-	if !skipLastKeyImage {
-		return keyImages
-	}
-
-	// Here we assume that there will be atleast one privkey
-	// which means there will be atleast one key image
-	keyImages = keyImages[:len(keyImages)-1]
 	return keyImages
 }
 
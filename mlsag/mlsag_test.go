@@ -37,6 +37,36 @@ func encodeDecodeSig(t *testing.T, sig *Signature, includeKeys bool) {
 	ok := sig.Equals(*decodedSig, includeKeys)
 	assert.True(t, ok)
 }
+
+func TestEncodeRejectsUndecodable(t *testing.T) {
+	proof := generateRandProof(3, 2)
+	sig, _, err := proof.prove(true)
+	assert.Nil(t, err)
+
+	// a ring larger than Decode accepts
+	tooLarge := *sig
+	tooLarge.r = append(append([]Responses{}, sig.r...), make([]Responses, MaxRingSize)...)
+	assert.NotNil(t, tooLarge.Encode(&bytes.Buffer{}, false))
+
+	// response vectors of different sizes
+	uneven := *sig
+	uneven.r = append([]Responses{}, sig.r...)
+	uneven.r[1] = uneven.r[1][:1]
+	assert.NotNil(t, uneven.Encode(&bytes.Buffer{}, false))
+
+	// fewer pubkey vectors than response vectors
+	missingKeys := *sig
+	missingKeys.PubKeys = sig.PubKeys[:2]
+	assert.NotNil(t, missingKeys.Encode(&bytes.Buffer{}, true))
+	assert.Nil(t, missingKeys.Encode(&bytes.Buffer{}, false))
+
+	// an empty signature can be decoded once encoded
+	buf := &bytes.Buffer{}
+	assert.Nil(t, (&Signature{}).Encode(buf, true))
+	assert.Nil(t, (&Signature{}).Decode(buf, true))
+	assert.Equal(t, 0, buf.Len())
+}
+
 func TestGenNonces(t *testing.T) {
 	for i := 1; i < 20; i++ {
 		nonces := generateNonces(i)
@@ -16,6 +16,9 @@ type Proof struct {
 	// in the matrix
 	privKeys PrivKeys
 
+	// linkable marks the columns for which a key image is produced
+	linkable []bool
+
 	//Signer pubkeys
 	signerPubKeys PubKeys
 
@@ -26,6 +29,23 @@ type Proof struct {
 
 	// message to be signed
 	msg []byte
+
+	// version of the signature to produce
+	version uint8
+}
+
+// SetVersion sets the version of the signature that will be produced.
+// By default SignatureV1 signatures are produced
+func (p *Proof) SetVersion(version uint8) {
+	p.version = version
+}
+
+// Version returns the version of the signature that will be produced
+func (p *Proof) Version() uint8 {
+	if p.version == 0 {
+		return SignatureV1
+	}
+	return p.version
 }
 
 func (p *Proof) addPubKeys(keys PubKeys) {
@@ -50,7 +70,19 @@ func (proof *Proof) addSignerPubKey() {
 	proof.addPubKeys(proof.signerPubKeys)
 }
 
+// AddSecret adds a private key to the signers key vector.
+// A key image is produced for the column of the key
 func (p *Proof) AddSecret(privKey ristretto.Scalar) {
+	p.addSecret(privKey, true)
+}
+
+// AddUnlinkableSecret adds a private key to the signers key vector
+// without producing a key image for its column
+func (p *Proof) AddUnlinkableSecret(privKey ristretto.Scalar) {
+	p.addSecret(privKey, false)
+}
+
+func (p *Proof) addSecret(privKey ristretto.Scalar, linkable bool) {
 
 	// Generate pubkey for given privkey
 	rawPubKey := privKeyToPubKey(privKey)
@@ -59,6 +91,36 @@ func (p *Proof) AddSecret(privKey ristretto.Scalar) {
 	p.signerPubKeys.AddPubKey(rawPubKey)
 	// Add privkey to signers set of priv keys
 	p.privKeys.AddPrivateKey(privKey)
+	p.linkable = append(p.linkable, linkable)
+}
+
+// SetMsg sets the message to be signed
+func (p *Proof) SetMsg(msg []byte) {
+	p.msg = msg
+}
+
+// Prove creates the signature and returns it with the key images
+// of the linkable columns, in column order
+func (p *Proof) Prove() (*Signature, []ristretto.Point, error) {
+	if len(p.privKeys) == 0 {
+		return nil, nil, errors.New("no private keys have been added to the proof")
+	}
+	return p.prove(false)
+}
+
+// linkableColumns returns the indices of the columns which produce
+// a key image. When skipLastKeyImage is set, the last column is never linkable
+func (p *Proof) linkableColumns(skipLastKeyImage bool) []int {
+	var columns []int
+	for i := range p.linkable {
+		if skipLastKeyImage && i == len(p.linkable)-1 {
+			break
+		}
+		if p.linkable[i] {
+			columns = append(columns, i)
+		}
+	}
+	return columns
 }
 
 func privKeyToPubKey(privkey ristretto.Scalar) ristretto.Point {
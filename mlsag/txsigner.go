@@ -0,0 +1,363 @@
+package mlsag
+
+import (
+	"errors"
+	"fmt"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+// genData is shared with the rangeproof, so that pseudo outputs and
+// output commitments use the same bases as the range proofs over them
+var genData = []byte("vosbor.BulletProof.v1")
+
+// txLabel separates the message signed by the inputs of a transaction
+var txLabel = []byte("vosbor.mlsag.tx")
+
+// TxInput is an output being spent, together with the decoys it is hidden among.
+// Each decoy holds the output key and the commitment of the decoy output
+type TxInput struct {
+	PrivKey ristretto.Scalar
+	Amount  ristretto.Scalar
+	Blinder ristretto.Scalar
+	Decoys  []PubKeys
+}
+
+// Commitment returns the commitment of the output being spent
+func (in TxInput) Commitment() ristretto.Point {
+	return commit(pedersen.New(genData), in.Amount, in.Blinder)
+}
+
+// TxOutput is an output being created
+type TxOutput struct {
+	Amount  ristretto.Scalar
+	Blinder ristretto.Scalar
+}
+
+// Commitment returns the commitment of the output
+func (out TxOutput) Commitment() ristretto.Point {
+	return commit(pedersen.New(genData), out.Amount, out.Blinder)
+}
+
+// TxSigner signs every input of a transaction with a DualKey MLSAG,
+// creating one pseudo output per input such that the pseudo outputs
+// balance the outputs and the fee
+type TxSigner struct {
+	ped *pedersen.Pedersen
+
+	inputs  []TxInput
+	outputs []TxOutput
+	fee     ristretto.Scalar
+
+	msg     []byte
+	version uint8
+}
+
+// NewTxSigner returns an empty TxSigner
+func NewTxSigner() *TxSigner {
+	t := &TxSigner{
+		ped: pedersen.New(genData),
+	}
+	t.fee.SetZero()
+	return t
+}
+
+// AddInput adds an input to be spent
+func (t *TxSigner) AddInput(in TxInput) {
+	t.inputs = append(t.inputs, in)
+}
+
+// AddOutput adds an output to be created
+func (t *TxSigner) AddOutput(out TxOutput) {
+	t.outputs = append(t.outputs, out)
+}
+
+// SetFee sets the public fee of the transaction
+func (t *TxSigner) SetFee(fee ristretto.Scalar) {
+	t.fee = fee
+}
+
+// SetMsg sets the message of the transaction. Every input signs it
+// together with the pseudo outputs, the outputs and the fee
+func (t *TxSigner) SetMsg(msg []byte) {
+	t.msg = msg
+}
+
+// SetVersion sets the version of the signatures that will be produced
+func (t *TxSigner) SetVersion(version uint8) {
+	t.version = version
+}
+
+// SignedTx holds the signatures of every input of a transaction
+// along with the commitments needed to check its balance
+type SignedTx struct {
+	Signatures []*Signature
+	KeyImages  []ristretto.Point
+
+	// Msg is the message of the transaction, see TxSigner.SetMsg
+	Msg []byte
+
+	// PseudoOutputs commit to the amount of each input under a fresh blinder
+	PseudoOutputs []ristretto.Point
+	Outputs       []ristretto.Point
+	Fee           ristretto.Scalar
+}
+
+// Sign creates the pseudo outputs and signs every input
+func (t *TxSigner) Sign() (*SignedTx, error) {
+
+	if len(t.inputs) == 0 || len(t.outputs) == 0 {
+		return nil, errors.New("a transaction needs at least one input and one output")
+	}
+
+	// sum(inputs) = sum(outputs) + fee
+	var inSum, outSum, outBlinders ristretto.Scalar
+	inSum.SetZero()
+	outSum.Set(&t.fee)
+	outBlinders.SetZero()
+
+	for i := range t.inputs {
+		inSum.Add(&inSum, &t.inputs[i].Amount)
+	}
+
+	tx := &SignedTx{
+		Msg: t.msg,
+		Fee: t.fee,
+	}
+	for i := range t.outputs {
+		outSum.Add(&outSum, &t.outputs[i].Amount)
+		outBlinders.Add(&outBlinders, &t.outputs[i].Blinder)
+		tx.Outputs = append(tx.Outputs, commit(t.ped, t.outputs[i].Amount, t.outputs[i].Blinder))
+	}
+
+	if !inSum.Equals(&outSum) {
+		return nil, errors.New("inputs do not balance the outputs and the fee")
+	}
+
+	// The pseudo output blinders sum to the output blinders
+	pseudoBlinders := make([]ristretto.Scalar, len(t.inputs))
+	last := len(t.inputs) - 1
+	pseudoBlinders[last].Set(&outBlinders)
+	for i := 0; i < last; i++ {
+		pseudoBlinders[i].Rand()
+		pseudoBlinders[last].Sub(&pseudoBlinders[last], &pseudoBlinders[i])
+	}
+
+	for i, in := range t.inputs {
+		tx.PseudoOutputs = append(tx.PseudoOutputs, commit(t.ped, in.Amount, pseudoBlinders[i]))
+	}
+
+	// Every input signs the whole transaction, so that no commitment
+	// nor the fee can be changed once signed
+	msg := txMessage(tx.Msg, tx.PseudoOutputs, tx.Outputs, tx.Fee)
+
+	for i, in := range t.inputs {
+
+		// The signers commitment minus the pseudo output is
+		// a commitment to zero with key blinder - pseudoBlinder
+		var commToZero ristretto.Scalar
+		commToZero.Sub(&in.Blinder, &pseudoBlinders[i])
+
+		dk := NewDualKey()
+		dk.SetVersion(t.version)
+		for _, decoy := range in.Decoys {
+			if decoy.Len() != 2 {
+				return nil, fmt.Errorf("decoy of input %d must hold an output key and a commitment", i)
+			}
+			dk.AddDecoy(PubKeys{keys: append([]ristretto.Point{}, decoy.keys...)})
+		}
+		dk.SubCommToZero(tx.PseudoOutputs[i])
+		dk.SetPrimaryKey(in.PrivKey)
+		dk.SetCommToZero(commToZero)
+		dk.SetMsg(msg)
+
+		sig, keyImage, err := dk.Prove()
+		if err != nil {
+			return nil, fmt.Errorf("could not sign input %d: %s", i, err)
+		}
+
+		tx.Signatures = append(tx.Signatures, sig)
+		tx.KeyImages = append(tx.KeyImages, keyImage)
+	}
+
+	return tx, nil
+}
+
+// Verify checks the signature of every input, that the pseudo outputs
+// balance the outputs and the fee, and that input i was signed over
+// rings[i], the ring of input i as found on the ledger. Each member of
+// a ring holds an output key and the commitment of that output.
+// Every signature must be a DualKey signature whose only key image
+// belongs to the output key, and no key image may be spent twice
+func (tx *SignedTx) Verify(rings [][]PubKeys) (bool, error) {
+
+	if len(tx.Signatures) == 0 || len(tx.Signatures) != len(tx.KeyImages) || len(tx.Signatures) != len(tx.PseudoOutputs) {
+		return false, errors.New("number of signatures, key images and pseudo outputs do not match")
+	}
+	if len(rings) != len(tx.Signatures) {
+		return false, errors.New("number of rings does not match the number of inputs")
+	}
+
+	spent := make(map[string]bool, len(tx.KeyImages))
+	for i := range tx.KeyImages {
+		key := string(tx.KeyImages[i].Bytes())
+		if spent[key] {
+			return false, fmt.Errorf("key image of input %d is spent twice", i)
+		}
+		spent[key] = true
+	}
+
+	msg := txMessage(tx.Msg, tx.PseudoOutputs, tx.Outputs, tx.Fee)
+
+	for i, sig := range tx.Signatures {
+		if sig == nil {
+			return false, fmt.Errorf("signature of input %d is missing", i)
+		}
+		if err := checkDualKey(sig); err != nil {
+			return false, fmt.Errorf("signature of input %d: %s", i, err)
+		}
+
+		ring, err := tx.Ring(i)
+		if err != nil {
+			return false, err
+		}
+		if !sameMembers(ring, rings[i]) {
+			return false, fmt.Errorf("ring of input %d does not match the ledger", i)
+		}
+
+		signed := *sig
+		signed.Msg = msg
+		ok, err := signed.Verify([]ristretto.Point{tx.KeyImages[i]})
+		if !ok || err != nil {
+			return false, fmt.Errorf("signature of input %d is invalid: %v", i, err)
+		}
+	}
+
+	if !tx.balances() {
+		return false, errors.New("pseudo outputs do not balance the outputs and the fee")
+	}
+	return true, nil
+}
+
+// checkDualKey returns an error unless every member of the ring holds an
+// output key and a commitment to zero, and the only key image of the
+// signature belongs to the output key. Otherwise the key image could be
+// taken over the commitment to zero, which is fresh for every transaction
+func checkDualKey(sig *Signature) error {
+	if len(sig.PubKeys) == 0 {
+		return errors.New("ring is empty")
+	}
+	for j := range sig.PubKeys {
+		if sig.PubKeys[j].Len() != 2 {
+			return errors.New("ring member is not a dual key")
+		}
+	}
+
+	columns, err := columnsFromMask(sig.Linkable, 2, 1)
+	if err != nil {
+		return err
+	}
+	if columns[0] != 0 {
+		return errors.New("key image does not belong to the output key")
+	}
+	return nil
+}
+
+// txMessage returns the message signed by every input, binding the
+// message of the transaction to its pseudo outputs, outputs and fee
+func txMessage(msg []byte, pseudoOutputs, outputs []ristretto.Point, fee ristretto.Scalar) []byte {
+	t := fiatshamir.NewTranscript(txLabel)
+	t.AppendMessage([]byte("msg"), msg)
+
+	t.AppendUint64([]byte("pseudo-outputs"), uint64(len(pseudoOutputs)))
+	for i := range pseudoOutputs {
+		t.AppendPoint([]byte("pseudo-output"), pseudoOutputs[i])
+	}
+
+	t.AppendUint64([]byte("outputs"), uint64(len(outputs)))
+	for i := range outputs {
+		t.AppendPoint([]byte("output"), outputs[i])
+	}
+
+	t.AppendScalar([]byte("fee"), fee)
+
+	digest := t.ChallengeScalar([]byte("digest"))
+	return digest.Bytes()
+}
+
+// sameMembers returns true if both rings hold the same members, in any order
+func sameMembers(a, b []PubKeys) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	count := make(map[string]int, len(a))
+	for i := range a {
+		count[memberKey(a[i])]++
+	}
+	for i := range b {
+		key := memberKey(b[i])
+		if count[key] == 0 {
+			return false
+		}
+		count[key]--
+	}
+	return true
+}
+
+// memberKey returns the encoding of the keys of a ring member
+func memberKey(member PubKeys) string {
+	buf := make([]byte, 0, 32*member.Len())
+	for i := range member.keys {
+		buf = append(buf, member.keys[i].Bytes()...)
+	}
+	return string(buf)
+}
+
+// balances returns true if sum(pseudoOutputs) = sum(outputs) + fee * H
+func (tx *SignedTx) balances() bool {
+	ped := pedersen.New(genData)
+
+	var lhs, rhs ristretto.Point
+	lhs.SetZero()
+	for i := range tx.PseudoOutputs {
+		lhs.Add(&lhs, &tx.PseudoOutputs[i])
+	}
+
+	rhs.ScalarMult(&ped.BasePoint, &tx.Fee)
+	for i := range tx.Outputs {
+		rhs.Add(&rhs, &tx.Outputs[i])
+	}
+	return lhs.Equals(&rhs)
+}
+
+// Ring returns the ring signed by input i, with the pseudo output added
+// back to the commitments so that it matches the outputs on the ledger
+func (tx *SignedTx) Ring(i int) ([]PubKeys, error) {
+	if i < 0 || i >= len(tx.Signatures) || i >= len(tx.PseudoOutputs) {
+		return nil, errors.New("input index is out of range")
+	}
+
+	sig := tx.Signatures[i]
+	ring := make([]PubKeys, len(sig.PubKeys))
+	for j := range sig.PubKeys {
+		if sig.PubKeys[j].Len() != 2 {
+			return nil, errors.New("ring member is not a dual key")
+		}
+		keys := append([]ristretto.Point{}, sig.PubKeys[j].keys...)
+		keys[1].Add(&keys[1], &tx.PseudoOutputs[i])
+		ring[j] = PubKeys{keys: keys}
+	}
+	return ring, nil
+}
+
+// commit returns amount * H + blinder * G
+func commit(ped *pedersen.Pedersen, amount, blinder ristretto.Scalar) ristretto.Point {
+	var c, blind ristretto.Point
+	c.ScalarMult(&ped.BasePoint, &amount)
+	blind.ScalarMult(&ped.BlindPoint, &blinder)
+	c.Add(&c, &blind)
+	return c
+}
@@ -0,0 +1,265 @@
+package mlsag
+
+import (
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+func TestTxSigner(t *testing.T) {
+	for _, numInputs := range []int{1, 2, 4} {
+		signer, inputs := generateRandTx(numInputs, 3, 11)
+
+		tx, err := signer.Sign()
+		require.Nil(t, err)
+		assert.Equal(t, numInputs, len(tx.Signatures))
+		assert.Equal(t, numInputs, len(tx.KeyImages))
+		assert.Equal(t, numInputs, len(tx.PseudoOutputs))
+		assert.Equal(t, 3, len(tx.Outputs))
+
+		ok, err := tx.Verify(ledgerRings(inputs))
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		for i, in := range inputs {
+			expected := CalculateKeyImage(in.PrivKey, privKeyToPubKey(in.PrivKey))
+			assert.True(t, expected.Equals(&tx.KeyImages[i]))
+		}
+	}
+}
+
+func TestTxSignerRing(t *testing.T) {
+	signer, inputs := generateRandTx(2, 1, 8)
+
+	tx, err := signer.Sign()
+	require.Nil(t, err)
+
+	for i, in := range inputs {
+		ring, err := tx.Ring(i)
+		require.Nil(t, err)
+		assert.Equal(t, len(in.Decoys)+1, len(ring))
+
+		// Every decoy and the real output appear with their ledger commitment,
+		// which also shows the decoys passed by the caller are left untouched
+		for _, e := range ledgerRing(in) {
+			found := false
+			for _, member := range ring {
+				if member.Equals(e) {
+					found = true
+				}
+			}
+			assert.True(t, found)
+		}
+	}
+
+	_, err = tx.Ring(2)
+	assert.NotNil(t, err)
+}
+
+func TestTxSignerUnbalanced(t *testing.T) {
+	signer, _ := generateRandTx(2, 2, 4)
+
+	var one ristretto.Scalar
+	one.SetOne()
+	signer.outputs[0].Amount.Add(&signer.outputs[0].Amount, &one)
+
+	_, err := signer.Sign()
+	assert.NotNil(t, err)
+}
+
+func TestTxSignerBadTx(t *testing.T) {
+
+	// delta * H can be computed by anyone, as H is public
+	var delta ristretto.Scalar
+	delta.SetBigInt(big.NewInt(5))
+	var deltaH ristretto.Point
+	deltaH.ScalarMult(&pedersen.New(genData).BasePoint, &delta)
+
+	tamper := map[string]func(tx *SignedTx, rings [][]PubKeys){
+		"pseudo output": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.PseudoOutputs[0].Rand()
+		},
+		"output": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.Outputs[1].Rand()
+		},
+		"fee": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.Fee.Rand()
+		},
+		"key image": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.KeyImages[1].Rand()
+		},
+		"missing signature": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.Signatures = tx.Signatures[1:]
+		},
+		"message": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.Msg = []byte("another message")
+		},
+		// the balance still holds, the signatures must not
+		"output to fee": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.Outputs[0].Sub(&tx.Outputs[0], &deltaH)
+			tx.Fee.Add(&tx.Fee, &delta)
+		},
+		"shifted pseudo outputs": func(tx *SignedTx, rings [][]PubKeys) {
+			tx.PseudoOutputs[0].Add(&tx.PseudoOutputs[0], &deltaH)
+			tx.PseudoOutputs[1].Sub(&tx.PseudoOutputs[1], &deltaH)
+		},
+		"ring not on the ledger": func(tx *SignedTx, rings [][]PubKeys) {
+			rings[0][0] = generateDecoy(2)
+		},
+		"missing ring": func(tx *SignedTx, rings [][]PubKeys) {
+			rings[1] = rings[1][1:]
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			signer, inputs := generateRandTx(2, 2, 4)
+			tx, err := signer.Sign()
+			require.Nil(t, err)
+
+			rings := ledgerRings(inputs)
+			fn(tx, rings)
+
+			ok, err := tx.Verify(rings)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestTxSignerSwappedLinkableColumn(t *testing.T) {
+	signer, inputs := generateRandTx(1, 2, 4)
+	tx, err := signer.Sign()
+	require.Nil(t, err)
+
+	// With a single input, the pseudo output blinder is the sum of the
+	// output blinders, so the spender knows the commitment to zero
+	in := inputs[0]
+	var commToZero ristretto.Scalar
+	commToZero.Set(&in.Blinder)
+	for _, out := range signer.outputs {
+		commToZero.Sub(&commToZero, &out.Blinder)
+	}
+
+	// Sign the same ring with the key image over the commitment to zero
+	dk := NewDualKey()
+	for _, decoy := range in.Decoys {
+		dk.AddDecoy(PubKeys{keys: append([]ristretto.Point{}, decoy.keys...)})
+	}
+	dk.SubCommToZero(tx.PseudoOutputs[0])
+	dk.AddUnlinkableSecret(in.PrivKey)
+	dk.AddSecret(commToZero)
+	dk.SetMsg(txMessage(tx.Msg, tx.PseudoOutputs, tx.Outputs, tx.Fee))
+
+	sig, keyImages, err := dk.Proof.Prove()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(keyImages))
+	assert.False(t, keyImages[0].Equals(&tx.KeyImages[0]))
+
+	// The signature itself is valid
+	ok, err := sig.Verify(keyImages)
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	tx.Signatures[0] = sig
+	tx.KeyImages[0] = keyImages[0]
+	ok, err = tx.Verify(ledgerRings(inputs))
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestTxSignerRepeatedKeyImage(t *testing.T) {
+	signer, inputs := generateRandTx(1, 1, 4)
+
+	// spend the same output twice, paying for it with a second output
+	signer.AddInput(inputs[0])
+	inputs = append(inputs, inputs[0])
+	var out TxOutput
+	out.Amount.SetBigInt(big.NewInt(100))
+	out.Blinder.Rand()
+	signer.AddOutput(out)
+
+	tx, err := signer.Sign()
+	require.Nil(t, err)
+	require.True(t, tx.KeyImages[0].Equals(&tx.KeyImages[1]))
+
+	ok, err := tx.Verify(ledgerRings(inputs))
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestTxSignerV2(t *testing.T) {
+	signer, inputs := generateRandTx(2, 2, 4)
+	signer.SetVersion(SignatureV2)
+
+	tx, err := signer.Sign()
+	require.Nil(t, err)
+	for _, sig := range tx.Signatures {
+		assert.Equal(t, SignatureV2, sig.Version())
+	}
+
+	ok, err := tx.Verify(ledgerRings(inputs))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+// generateRandTx returns a balanced transaction with a fee of 10
+func generateRandTx(numInputs, numOutputs, ringSize int) (*TxSigner, []TxInput) {
+	signer := NewTxSigner()
+
+	var fee ristretto.Scalar
+	fee.SetBigInt(big.NewInt(10))
+	signer.SetFee(fee)
+	signer.SetMsg([]byte("hello world"))
+
+	var inputs []TxInput
+	var total int64
+	for i := 0; i < numInputs; i++ {
+		amount := int64(100 * (i + 1))
+		total += amount
+
+		var in TxInput
+		in.PrivKey.Rand()
+		in.Amount.SetBigInt(big.NewInt(amount))
+		in.Blinder.Rand()
+		in.Decoys = generateDecoys(ringSize-1, 2)
+
+		signer.AddInput(in)
+		inputs = append(inputs, in)
+	}
+
+	total -= 10
+	for i := 0; i < numOutputs; i++ {
+		amount := total / int64(numOutputs)
+		if i == numOutputs-1 {
+			amount = total - amount*int64(numOutputs-1)
+		}
+
+		var out TxOutput
+		out.Amount.SetBigInt(big.NewInt(amount))
+		out.Blinder.Rand()
+		signer.AddOutput(out)
+	}
+	return signer, inputs
+}
+
+// ledgerRing returns the ring of an input as found on the ledger
+func ledgerRing(in TxInput) []PubKeys {
+	var real PubKeys
+	real.AddPubKey(privKeyToPubKey(in.PrivKey))
+	real.AddPubKey(in.Commitment())
+	return append(append([]PubKeys{}, in.Decoys...), real)
+}
+
+// ledgerRings returns the ring of every input
+func ledgerRings(inputs []TxInput) [][]PubKeys {
+	rings := make([][]PubKeys, len(inputs))
+	for i := range inputs {
+		rings[i] = ledgerRing(inputs[i])
+	}
+	return rings
+}
@@ -0,0 +1,321 @@
+package mlsag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// SignatureV1 is the first version of the versioned signature wire format.
+// Its challenges are derived by hashing the message with the ring points
+const SignatureV1 uint8 = 1
+
+const (
+	// MaxRingSize is the maximum number of ring members accepted when decoding a signature
+	MaxRingSize = 1 << 10
+
+	// MaxKeys is the maximum number of keys per ring member accepted when decoding a signature
+	MaxKeys = 1 << 4
+
+	// maxEnvelopeSize is the size of the largest signature that can be encoded
+	// version + flags + c + lenR + numResponses + responses + pubkeys + numKeyImages + keyImages + linkable
+	maxEnvelopeSize = 2 + 32 + 4 + 4 + (2 * MaxRingSize * MaxKeys * 32) + 4 + (MaxKeys * 32) + 4
+)
+
+// flags describing the optional sections of a versioned signature
+const (
+	flagPubKeys uint8 = 1 << iota
+	flagKeyImages
+	flagLinkable
+)
+
+const knownFlags = flagPubKeys | flagKeyImages | flagLinkable
+
+// EncodeVersioned writes the signature using the versioned wire format.
+// The pubkeys and the key images are written only when requested,
+// the linkable mask whenever the signature has one
+func (s *Signature) EncodeVersioned(w io.Writer, encodeKeys, encodeKeyImages bool) error {
+
+	lenR, numResponses, err := s.checkEncodable(encodeKeys)
+	if err != nil {
+		return err
+	}
+
+	var flags uint8
+	if encodeKeys {
+		flags |= flagPubKeys
+	}
+
+	if s.Linkable != nil {
+		if uint32(len(s.Linkable)) != numResponses {
+			return errors.New("linkable mask must cover every key of a member")
+		}
+		flags |= flagLinkable
+	}
+
+	if encodeKeyImages {
+		// the key images must be those of the linkable columns
+		_, err = columnsFromMask(s.Linkable, int(numResponses), len(s.KeyImages))
+		if err != nil {
+			return err
+		}
+		flags |= flagKeyImages
+	}
+
+	err = binary.Write(w, binary.BigEndian, []uint8{s.Version(), flags})
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, s.c.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, lenR)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, numResponses)
+	if err != nil {
+		return err
+	}
+
+	for i := range s.r {
+		err = s.r[i].Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	if encodeKeys {
+		for i := range s.PubKeys {
+			err = s.PubKeys[i].Encode(w)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if encodeKeyImages {
+		err = binary.Write(w, binary.BigEndian, uint32(len(s.KeyImages)))
+		if err != nil {
+			return err
+		}
+		for i := range s.KeyImages {
+			err = binary.Write(w, binary.BigEndian, s.KeyImages[i].Bytes())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Linkable == nil {
+		return nil
+	}
+	return binary.Write(w, binary.BigEndian, encodeMask(s.Linkable))
+}
+
+// DecodeVersioned reads a signature written with EncodeVersioned.
+// The optional sections are decoded according to the flags in the encoding
+func (s *Signature) DecodeVersioned(r io.Reader) error {
+
+	if s == nil {
+		return errors.New("struct is nil")
+	}
+
+	var header [2]uint8
+	err := binary.Read(r, binary.BigEndian, &header)
+	if err != nil {
+		return err
+	}
+
+	version, flags := header[0], header[1]
+	if version != SignatureV1 && version != SignatureV2 {
+		return fmt.Errorf("unsupported signature version %d", version)
+	}
+	if flags&^knownFlags != 0 {
+		return fmt.Errorf("unknown signature flags %#x", flags)
+	}
+
+	s.version = version
+
+	err = readerToScalar(r, &s.c)
+	if err != nil {
+		return err
+	}
+
+	var lenR, numResponses uint32
+	err = binary.Read(r, binary.BigEndian, &lenR)
+	if err != nil {
+		return err
+	}
+	err = binary.Read(r, binary.BigEndian, &numResponses)
+	if err != nil {
+		return err
+	}
+
+	err = checkBounds(lenR, numResponses)
+	if err != nil {
+		return err
+	}
+
+	s.r = make([]Responses, lenR)
+	for i := uint32(0); i < lenR; i++ {
+		err = s.r[i].Decode(r, numResponses)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.PubKeys = nil
+	if flags&flagPubKeys != 0 {
+		s.PubKeys = make([]PubKeys, lenR)
+		for i := uint32(0); i < lenR; i++ {
+			err = s.PubKeys[i].Decode(r, numResponses)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	s.KeyImages = nil
+	if flags&flagKeyImages != 0 {
+		var numKeyImages uint32
+		err = binary.Read(r, binary.BigEndian, &numKeyImages)
+		if err != nil {
+			return err
+		}
+		if numKeyImages > numResponses {
+			return errors.New("there cannot be more key images than keys per member")
+		}
+
+		s.KeyImages = make([]ristretto.Point, numKeyImages)
+		for i := range s.KeyImages {
+			err = readerToPoint(r, &s.KeyImages[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	s.Linkable = nil
+	if flags&flagLinkable == 0 {
+		return nil
+	}
+
+	var mask uint32
+	err = binary.Read(r, binary.BigEndian, &mask)
+	if err != nil {
+		return err
+	}
+	s.Linkable, err = decodeMask(mask, numResponses)
+	if err != nil {
+		return err
+	}
+
+	if flags&flagKeyImages == 0 {
+		return nil
+	}
+	_, err = columnsFromMask(s.Linkable, int(numResponses), len(s.KeyImages))
+	return err
+}
+
+// WriteSignature writes the versioned encoding of the signature prefixed
+// with its length, so that multiple signatures can be written back-to-back
+func WriteSignature(w io.Writer, sig *Signature, encodeKeys, encodeKeyImages bool) error {
+	buf := &bytes.Buffer{}
+	err := sig.EncodeVersioned(buf, encodeKeys, encodeKeyImages)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(buf.Len()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// ReadSignature reads a single length prefixed signature written with WriteSignature
+func ReadSignature(r io.Reader) (*Signature, error) {
+
+	var size uint32
+	err := binary.Read(r, binary.BigEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxEnvelopeSize {
+		return nil, fmt.Errorf("signature size %d exceeds the maximum of %d bytes", size, maxEnvelopeSize)
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewReader(payload)
+	sig := &Signature{}
+	err = sig.DecodeVersioned(buf)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 0 {
+		return nil, errors.New("unexpected trailing bytes after signature")
+	}
+	return sig, nil
+}
+
+// checkEncodable makes sure that the signature can be decoded once
+// encoded, and returns the number of members and keys per member
+func (s *Signature) checkEncodable(encodeKeys bool) (uint32, uint32, error) {
+
+	lenR := uint32(len(s.r))
+	var numResponses uint32
+	if lenR > 0 {
+		numResponses = uint32(s.r[0].Len())
+	}
+
+	err := checkBounds(lenR, numResponses)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range s.r {
+		if uint32(s.r[i].Len()) != numResponses {
+			return 0, 0, errors.New("all response vectors must be the same size")
+		}
+	}
+
+	if !encodeKeys {
+		return lenR, numResponses, nil
+	}
+
+	if uint32(len(s.PubKeys)) != lenR {
+		return 0, 0, errors.New("number of pubkey vectors does not match the number of response vectors")
+	}
+	for i := range s.PubKeys {
+		if uint32(s.PubKeys[i].Len()) != numResponses {
+			return 0, 0, errors.New("all pubkey vectors must be the same size as the response vectors")
+		}
+	}
+	return lenR, numResponses, nil
+}
+
+// checkBounds makes sure that the amount of ring members and keys
+// claimed by an encoding are within the accepted limits
+func checkBounds(lenR, numResponses uint32) error {
+	if lenR > MaxRingSize {
+		return fmt.Errorf("ring size %d exceeds the maximum of %d", lenR, MaxRingSize)
+	}
+	if numResponses > MaxKeys {
+		return fmt.Errorf("number of keys %d exceeds the maximum of %d", numResponses, MaxKeys)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package mlsag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeVersioned(t *testing.T) {
+
+	for _, includeKeys := range []bool{false, true} {
+		for _, includeKeyImages := range []bool{false, true} {
+
+			proof := generateRandProof(11, 3)
+			sig, keyImages, err := proof.prove(true)
+			require.Nil(t, err)
+			assert.Equal(t, keyImages, sig.KeyImages)
+
+			buf := &bytes.Buffer{}
+			err = sig.EncodeVersioned(buf, includeKeys, includeKeyImages)
+			require.Nil(t, err)
+
+			decodedSig := &Signature{}
+			err = decodedSig.DecodeVersioned(buf)
+			require.Nil(t, err)
+			assert.Equal(t, 0, buf.Len())
+
+			assert.True(t, sig.Equals(*decodedSig, includeKeys))
+
+			if !includeKeyImages {
+				assert.Nil(t, decodedSig.KeyImages)
+				continue
+			}
+
+			require.Equal(t, len(keyImages), len(decodedSig.KeyImages))
+			for i := range keyImages {
+				assert.True(t, keyImages[i].Equals(&decodedSig.KeyImages[i]))
+			}
+
+			if includeKeys {
+				decodedSig.Msg = sig.Msg
+				ok, err := decodedSig.Verify(decodedSig.KeyImages)
+				assert.Nil(t, err)
+				assert.True(t, ok)
+			}
+		}
+	}
+}
+
+func TestSignatureStream(t *testing.T) {
+
+	var sigs []*Signature
+	buf := &bytes.Buffer{}
+
+	for i := 0; i < 5; i++ {
+		proof := generateRandProof(i+2, 2)
+		sig, _, err := proof.prove(true)
+		require.Nil(t, err)
+
+		err = WriteSignature(buf, sig, true, true)
+		require.Nil(t, err)
+		sigs = append(sigs, sig)
+	}
+
+	for i := range sigs {
+		decodedSig, err := ReadSignature(buf)
+		require.Nil(t, err)
+		assert.True(t, sigs[i].Equals(*decodedSig, true))
+		assert.Equal(t, len(sigs[i].KeyImages), len(decodedSig.KeyImages))
+	}
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestDecodeVersionedRejectsHostileLengths(t *testing.T) {
+
+	var c [32]byte
+
+	tests := []struct {
+		name         string
+		lenR         uint32
+		numResponses uint32
+	}{
+		{"ring size", MaxRingSize + 1, 2},
+		{"key count", 2, MaxKeys + 1},
+		{"huge ring size", 0xFFFFFFFF, 2},
+		{"huge key count", 2, 0xFFFFFFFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			buf.Write([]byte{SignatureV1, 0})
+			buf.Write(c[:])
+			binary.Write(buf, binary.BigEndian, tt.lenR)
+			binary.Write(buf, binary.BigEndian, tt.numResponses)
+
+			legacy := bytes.NewReader(buf.Bytes()[2:])
+
+			err := (&Signature{}).DecodeVersioned(buf)
+			assert.NotNil(t, err)
+
+			err = (&Signature{}).Decode(legacy, true)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestDecodeVersionedMalformed(t *testing.T) {
+	proof := generateRandProof(4, 2)
+	sig, _, err := proof.prove(true)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.EncodeVersioned(buf, true, true)
+	require.Nil(t, err)
+	encoded := buf.Bytes()
+
+	// unknown version
+	badVersion := append([]byte{}, encoded...)
+	badVersion[0] = 0xFF
+	err = (&Signature{}).DecodeVersioned(bytes.NewReader(badVersion))
+	assert.NotNil(t, err)
+
+	// unknown flags
+	badFlags := append([]byte{}, encoded...)
+	badFlags[1] = 0x80
+	err = (&Signature{}).DecodeVersioned(bytes.NewReader(badFlags))
+	assert.NotNil(t, err)
+
+	// truncated
+	err = (&Signature{}).DecodeVersioned(bytes.NewReader(encoded[:len(encoded)-1]))
+	assert.NotNil(t, err)
+}
+
+func TestReadSignatureMalformedEnvelope(t *testing.T) {
+	proof := generateRandProof(4, 2)
+	sig, _, err := proof.prove(true)
+	require.Nil(t, err)
+
+	// size larger than any valid signature
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(maxEnvelopeSize+1))
+	_, err = ReadSignature(buf)
+	assert.NotNil(t, err)
+
+	// trailing bytes inside the envelope
+	payload := &bytes.Buffer{}
+	err = sig.EncodeVersioned(payload, false, false)
+	require.Nil(t, err)
+	payload.WriteByte(0)
+
+	buf.Reset()
+	binary.Write(buf, binary.BigEndian, uint32(payload.Len()))
+	buf.Write(payload.Bytes())
+	_, err = ReadSignature(buf)
+	assert.NotNil(t, err)
+
+	// envelope shorter than advertised
+	buf.Reset()
+	binary.Write(buf, binary.BigEndian, uint32(100))
+	buf.Write(make([]byte, 10))
+	_, err = ReadSignature(buf)
+	assert.NotNil(t, err)
+}
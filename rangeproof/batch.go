@@ -0,0 +1,130 @@
+package rangeproof
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
+)
+
+// InvalidProofsError lists the indices of the proofs of a batch that
+// do not verify
+type InvalidProofsError struct {
+	Indices []int
+}
+
+func (e *InvalidProofsError) Error() string {
+	return fmt.Sprintf("invalid proofs at indices %v", e.Indices)
+}
+
+// VerifyBatch verifies many proofs at once. The verification equation
+// of every proof is weighted by a random scalar and all of them are
+// summed into a single multiscalar multiplication, in which the
+// generators shared by the proofs only appear once. Proofs may use
+// different bit widths, aggregation sizes and generator versions. If the batch does not
+// verify, the proofs are checked one by one and an *InvalidProofsError
+// lists the invalid ones
+func VerifyBatch(proofs []Proof) (bool, error) {
+
+	if len(proofs) == 0 {
+		return false, errors.New("batch cannot be empty")
+	}
+
+	// the generators are hash chains or indexed hashes, so the
+	// generators of a smaller proof are a prefix of the largest ones
+	// of the same version
+	var sizes [IndexedGenerators + 1]uint32
+	for i := range proofs {
+		if err := proofs[i].checkSize(); err != nil {
+			return false, errors.Wrapf(err, "proof %d", i)
+		}
+		if nm := proofs[i].N * proofs[i].M; nm > sizes[proofs[i].Generators] {
+			sizes[proofs[i].Generators] = nm
+		}
+	}
+
+	// weights of the G and H vectors of every version
+	var g, h [IndexedGenerators + 1][]ristretto.Scalar
+	for version, size := range sizes {
+		g[version] = make([]ristretto.Scalar, size)
+		h[version] = make([]ristretto.Scalar, size)
+		for i := range g[version] {
+			g[version][i].SetZero()
+			h[version][i].SetZero()
+		}
+	}
+
+	var gBP, hBP ristretto.Scalar
+	gBP.SetZero()
+	hBP.SetZero()
+
+	var scalars []ristretto.Scalar
+	var points []ristretto.Point
+
+	for i := range proofs {
+		p := &proofs[i]
+		x, y, z, w := p.challenges()
+
+		eq, err := megacheckTerms(p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, p.S, p.T1, p.T2, p.V, p.N, p.M)
+		if err != nil {
+			return false, errors.Wrapf(err, "proof %d", i)
+		}
+
+		var r ristretto.Scalar
+		r.Rand()
+		eq.mul(r)
+
+		gv, hv := g[p.Generators], h[p.Generators]
+		for j := range eq.g {
+			gv[j].Add(&gv[j], &eq.g[j])
+			hv[j].Add(&hv[j], &eq.h[j])
+		}
+		gBP.Add(&gBP, &eq.gBP)
+		hBP.Add(&hBP, &eq.hBP)
+
+		scalars = append(scalars, eq.scalars...)
+		points = append(points, eq.points...)
+	}
+
+	// the value and blinding bases do not depend on the version
+	gens := newGenerators(DefaultGenerators, 0)
+	scalars = append(scalars, gBP, hBP)
+	points = append(points, gens.ped.BasePoint, gens.ped.BlindPoint)
+
+	for version, size := range sizes {
+		if size == 0 {
+			continue
+		}
+		gens := newGenerators(uint8(version), size)
+		scalars = append(scalars, g[version]...)
+		points = append(points, gens.ped.BaseVector.Bases...)
+		scalars = append(scalars, h[version]...)
+		points = append(points, gens.ped2.BaseVector.Bases...)
+	}
+
+	sum, err := msm.VarTimeMultiScalarMult(scalars, points)
+	if err != nil {
+		return false, err
+	}
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if zero.Equals(&sum) {
+		return true, nil
+	}
+
+	// pinpoint the invalid proofs
+	invalid := &InvalidProofsError{}
+	for i := range proofs {
+		if ok, err := Verify(proofs[i]); !ok || err != nil {
+			invalid.Indices = append(invalid.Indices, i)
+		}
+	}
+	if len(invalid.Indices) == 0 {
+		// only reachable with negligible probability
+		return false, errors.New("batch verification failed")
+	}
+	return false, invalid
+}
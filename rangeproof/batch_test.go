@@ -0,0 +1,124 @@
+package rangeproof
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	proofs := mixedProofs(t)
+
+	ok, err := VerifyBatch(proofs)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// a single proof is a batch too
+	ok, err = VerifyBatch(proofs[:1])
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyBatchInvalid(t *testing.T) {
+	proofs := mixedProofs(t)
+
+	// tamper with two proofs of different sizes
+	proofs[1].t.Rand()
+	proofs[3].A.Rand()
+
+	ok, err := VerifyBatch(proofs)
+	assert.False(t, ok)
+	require.NotNil(t, err)
+
+	invalid, isInvalid := err.(*InvalidProofsError)
+	require.True(t, isInvalid)
+	assert.Equal(t, []int{1, 3}, invalid.Indices)
+}
+
+func TestVerifyBatchSwappedCommitment(t *testing.T) {
+	proofs := mixedProofs(t)
+
+	// every proof is valid on its own, but not for the commitment of another
+	proofs[0].V[0], proofs[2].V[0] = proofs[2].V[0], proofs[0].V[0]
+
+	ok, err := VerifyBatch(proofs)
+	assert.False(t, ok)
+	require.NotNil(t, err)
+
+	invalid, isInvalid := err.(*InvalidProofsError)
+	require.True(t, isInvalid)
+	assert.Equal(t, []int{0, 2}, invalid.Indices)
+}
+
+func TestVerifyBatchMalformed(t *testing.T) {
+	_, err := VerifyBatch(nil)
+	assert.NotNil(t, err)
+
+	proofs := mixedProofs(t)
+	proofs[2].M = 3
+	_, err = VerifyBatch(proofs)
+	assert.NotNil(t, err)
+
+	proofs = mixedProofs(t)
+	proofs[1].IPProof = nil
+	_, err = VerifyBatch(proofs)
+	assert.NotNil(t, err)
+}
+
+// mixedProofs returns valid proofs of differing bit widths,
+// aggregation sizes and generator versions
+func mixedProofs(t testing.TB) []Proof {
+	sizes := []struct {
+		version uint8
+		n       uint32
+		m       int
+	}{
+		{DefaultGenerators, 64, 1},
+		{DefaultGenerators, 64, 4},
+		{DefaultGenerators, 32, 2},
+		{DefaultGenerators, 8, 3},
+		{ChainedGenerators, 64, 16},
+	}
+
+	proofs := make([]Proof, len(sizes))
+	for i, size := range sizes {
+		values := make([]*big.Int, size.m)
+		for j := range values {
+			values[j] = big.NewInt(int64(i*10 + j))
+		}
+		openings := commitToValues(values)
+
+		p, err := prove(size.version, size.n, openings, false)
+		require.Nil(t, err)
+		proofs[i] = p
+	}
+	return proofs
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	for _, count := range []int{1, 8, 32} {
+		proofs := make([]Proof, count)
+		for i := range proofs {
+			openings := randomValues(2)
+			p, err := Prove(openings, false)
+			require.Nil(b, err)
+			proofs[i] = p
+		}
+
+		b.Run(fmt.Sprintf("Individual/%d", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range proofs {
+					Verify(proofs[j])
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Batch/%d", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyBatch(proofs)
+			}
+		})
+	}
+}
@@ -13,12 +13,18 @@ type BitCommitment struct {
 }
 
 // BitCommit will take the value v producing aL and aR
-// N.B. This has been specialised for N <= 64
+// over N bits
 func BitCommit(v *big.Int) BitCommitment {
+	return bitCommit(v, N)
+}
+
+// bitCommit produces aL and aR over n bits
+// N.B. This has been specialised for n <= 64
+func bitCommit(v *big.Int, n uint32) BitCommitment {
 
 	bc := BitCommitment{
-		AL: make([]ristretto.Scalar, N),
-		AR: make([]ristretto.Scalar, N),
+		AL: make([]ristretto.Scalar, n),
+		AR: make([]ristretto.Scalar, n),
 	}
 
 	var zero ristretto.Scalar
@@ -30,7 +36,7 @@ func BitCommit(v *big.Int) BitCommitment {
 
 	num := v.Uint64()
 
-	for i := 0; i < N; i++ {
+	for i := uint32(0); i < n; i++ {
 
 		var rem uint64
 
@@ -62,7 +68,7 @@ func (b *BitCommitment) Debug(v *big.Int) error {
 	testAL := big.NewInt(0)
 	testAR := big.NewInt(0)
 
-	for i := 0; i < N; i++ {
+	for i := 0; i < len(b.AL); i++ {
 
 		var basePow, e = big.NewInt(2), big.NewInt(int64(i))
 		basePow.Exp(basePow, e, nil)
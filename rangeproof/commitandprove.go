@@ -10,7 +10,8 @@ import (
 )
 
 /*
-    Commitment struct is responsible for storing
+	Commitment struct is responsible for storing
+
 a commitment that is compatible with proving algorithm.
 It must be possible to serialize it into bytes and
 deserialized back into the correct format.
@@ -20,50 +21,58 @@ type Commitment struct {
 }
 
 /*
-    RangeProof stores a zero knowledge proof that
-a secret belongs to a certain interval.
+	RangeProof stores a zero knowledge proof that
+
+a secret belongs to a certain interval. It only holds
+public data: the commitments are stored without their
+blinding factors, and C is the base64 encoding of the
+commitment CC, so that callers can refer to it as text.
 */
 type RangeProof struct {
-	P Proof
-	A int64
-	CA Commitment
+	P    Proof
+	A    int64
+	CA   Commitment
 	CApC Commitment
-	B int64
-	CB Commitment
+	B    int64
+	CB   Commitment
 	CBpC Commitment
-	CC Commitment
-	C string
+	CC   Commitment
+	C    string
 }
 
 /*
-    Commit function is responsible for generation of
+	Commit function is responsible for generation of
+
 commitment to secret value v.
 */
 func Commit(v int64) (Commitment, error) {
 
-	genData := []byte("vosbor.BulletProof.v1")
+	var blinder ristretto.Scalar
+	blinder.Rand()
+
+	return CommitWithBlinder(v, blinder), nil
+}
+
+// CommitWithBlinder commits to v with the given blinding factor, for
+// instance one derived with pedersen.DeriveBlinder
+func CommitWithBlinder(v int64, blinder ristretto.Scalar) Commitment {
+
 	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((M * N)))
 
 	var amount ristretto.Scalar
 	amount.SetBigInt(big.NewInt(v))
-	c := ped.CommitToScalar(amount)
 
-	output := Commitment{
-		PedersenCommitment: c,
+	return Commitment{
+		PedersenCommitment: ped.CommitWithBlinder(amount, blinder),
 	}
-
-	return output, nil
 }
 
 /*
-   Recalculate the commitment and compare.
+Recalculate the commitment and compare.
 */
 func VerifyCommit(v int64, c Commitment) bool {
 
-	genData := []byte("vosbor.BulletProof.v1")
 	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((M * N)))
 
 	var amount ristretto.Scalar
 	amount.SetBigInt(big.NewInt(v))
@@ -71,74 +80,77 @@ func VerifyCommit(v int64, c Commitment) bool {
 	return ped.VerifyCommitment(amount, c.PedersenCommitment)
 }
 
+var (
+	// ErrInvalidBounds is returned when the lower bound is not less than the upper bound
+	ErrInvalidBounds = errors.New("lower bound must be less than the upper bound")
+	// ErrLowerBound is returned when CA or CApC do not match the lower bound A
+	ErrLowerBound = errors.New("commitment is inconsistent with lower bound A")
+	// ErrUpperBound is returned when CB or CBpC do not match the upper bound B
+	ErrUpperBound = errors.New("commitment is inconsistent with upper bound B")
+	// ErrCommitmentMismatch is returned when the bulletproof is not about CBpC and CApC
+	ErrCommitmentMismatch = errors.New("range proof does not prove the bound commitments")
+	// ErrInvalidProof is returned when the bulletproof is malformed or does not verify
+	ErrInvalidProof = errors.New("range proof is not valid")
+)
+
 /*
-    GenProofs computes a zero knowledge proofs that shows
+	GenProofs computes a zero knowledge proofs that shows
+
 v belongs to the interval [a, b) and is the correctly tied
-together with the commitment c.
+together with the commitment c. The upper bound b is excluded:
+the proof shows that v - a and v + 2^N - b both lie in [0, 2^N),
+so GenProof fails for v = b.
 */
 func GenProof(v int64, c Commitment, a int64, b int64) (RangeProof, error) {
 
 	if !VerifyCommit(v, c) {
 		return RangeProof{}, errors.New("Invalid commitment")
 	}
-
-	amounts := []ristretto.Scalar{}
-	commitments := make([]pedersen.Commitment, 0, M)
+	if a >= b {
+		return RangeProof{}, ErrInvalidBounds
+	}
 
 	// convert commitment to base64 value, blinding factor remains hidden
 	c_v := base64.StdEncoding.EncodeToString(c.PedersenCommitment.Commit.Bytes())
 
-	// N is number of bits in range
-	// So amount will be between 0...2^(N-1)
-	const N = 64
-
-	genData_b := []byte("vosbor.BulletProof.b")
-	ped_b := pedersen.New(genData_b)
-	ped_b.BaseVector.Compute(uint32((M * N)))
-	genData_a := []byte("vosbor.BulletProof.a")
-	ped_a := pedersen.New(genData_a)
-	ped_a.BaseVector.Compute(uint32((M * N)))
-
-	b2 := big.NewInt(2)
-	bn := big.NewInt(N)
-	b2.Exp(b2, bn, nil)
-	b2.Sub(b2, big.NewInt(1))
-
-	bigv_a := big.NewInt(a)
-	bigv_a = bigv_a.Sub(big.NewInt(v), bigv_a)
-
-	bb := big.NewInt(b)
-	bigv_b := b2.Sub(b2, bb)
-	bigv_b = bigv_b.Add(big.NewInt(v), bigv_b)
-
+	// v - a and v + 2^N - b both lie in [0, 2^N) exactly when v lies in [a, b)
+	bigv_a := new(big.Int).Sub(big.NewInt(v), big.NewInt(a))
+	bigv_b := new(big.Int).Add(big.NewInt(v), upperOffset(b))
 
 	var amount_b ristretto.Scalar
 	var amount_a ristretto.Scalar
 	amount_b.SetBigInt(bigv_b)
 	amount_a.SetBigInt(bigv_a)
 
-	c_b := ped_b.CommitToScalar(amount_b)
-	c_a := ped_a.CommitToScalar(amount_a)
+	// CB and CA commit to the public offsets 2^N - b and a without
+	// blinding, so that the verifier can recompute them, and so that
+	// C + CB opens to amount_b and C - CA opens to amount_a
+	c_b := boundCommitment(upperOffset(b))
+	c_a := boundCommitment(big.NewInt(a))
 	c_cb := pedersen.Add(c.PedersenCommitment, c_b)
 	c_ca := pedersen.Sub(c.PedersenCommitment, c_a)
 
-	amounts = append(amounts, amount_b)
-	amounts = append(amounts, amount_a)
-	commitments = append(commitments, c_cb)
-	commitments = append(commitments, c_ca)
-
-	p, err := Prove(amounts, commitments, true)
-
-	output := RangeProof {
-		P: p,
-		A: a,
-		CA: Commitment{ PedersenCommitment: c_a },
-		CApC: Commitment{ PedersenCommitment: c_ca },
-		B: b,
-		CB: Commitment{ PedersenCommitment: c_b },
-		CBpC: Commitment{ PedersenCommitment: c_cb },
-		CC: c,
-		C: c_v,
+	// the bound commitments are not blinded, so both open with the blinder of c
+	blinder := c.PedersenCommitment.BlindingFactor
+	openings := []Opening{{amount_b, blinder}, {amount_a, blinder}}
+
+	p, err := Prove(openings, false)
+
+	// the proof is public, so the blinder of c must not leak through it
+	for i := range p.V {
+		p.V[i] = publicCommitment(p.V[i])
+	}
+
+	output := RangeProof{
+		P:    p,
+		A:    a,
+		CA:   Commitment{PedersenCommitment: c_a},
+		CApC: Commitment{PedersenCommitment: publicCommitment(c_ca)},
+		B:    b,
+		CB:   Commitment{PedersenCommitment: c_b},
+		CBpC: Commitment{PedersenCommitment: publicCommitment(c_cb)},
+		CC:   Commitment{PedersenCommitment: publicCommitment(c.PedersenCommitment)},
+		C:    c_v,
 	}
 
 	return output, err
@@ -146,27 +158,81 @@ func GenProof(v int64, c Commitment, a int64, b int64) (RangeProof, error) {
 }
 
 /*
-    VerifyProof takes as input a zero knowledge proofs and
-returns true if a valid proof, and false otherwise.
+	VerifyProof takes as input a zero knowledge proofs and
+
+returns nil if it is a valid proof that the value committed
+in CC lies in [A, B), B being excluded, and one of the Err
+values of this package otherwise.
 */
 func VerifyProof(p RangeProof) (err error) {
 
-	if !(p.CApC.PedersenCommitment.Equals(pedersen.Sub(
-			p.CC.PedersenCommitment,
-			p.CA.PedersenCommitment))) {
-		return errors.New("Commitment is inconsistent with lower bound A.")
+	if p.A >= p.B {
+		return ErrInvalidBounds
+	}
+
+	// the bound commitments are public, recompute them
+	c_a := boundCommitment(big.NewInt(p.A))
+	c_b := boundCommitment(upperOffset(p.B))
+
+	if !p.CA.PedersenCommitment.EqualValue(c_a) {
+		return ErrLowerBound
+	}
+	if !p.CApC.PedersenCommitment.EqualValue(pedersen.Sub(p.CC.PedersenCommitment, c_a)) {
+		return ErrLowerBound
+	}
+	if !p.CB.PedersenCommitment.EqualValue(c_b) {
+		return ErrUpperBound
 	}
-	if !(p.CBpC.PedersenCommitment.Equals(pedersen.Add(
-		p.CC.PedersenCommitment,
-		p.CB.PedersenCommitment))) {
-		return errors.New("Commitment is inconsistent with lower bound B.")
+	if !p.CBpC.PedersenCommitment.EqualValue(pedersen.Add(p.CC.PedersenCommitment, c_b)) {
+		return ErrUpperBound
+	}
+
+	// the bulletproof must be about exactly C + CB and C - CA
+	if p.P.N != N || len(p.P.V) != 2 {
+		return ErrCommitmentMismatch
+	}
+	if !p.P.V[0].EqualValue(p.CBpC.PedersenCommitment) || !p.P.V[1].EqualValue(p.CApC.PedersenCommitment) {
+		return ErrCommitmentMismatch
 	}
 
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
+			err = ErrInvalidProof
 		}
 	}()
-	Verify(p.P)
-	return err
+	if ok, err := Verify(p.P); !ok || err != nil {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// upperOffset returns 2^N - b
+func upperOffset(b int64) *big.Int {
+	offset := new(big.Int).Lsh(big.NewInt(1), N)
+	return offset.Sub(offset, big.NewInt(b))
+}
+
+// boundCommitment commits to a public bound without blinding
+func boundCommitment(x *big.Int) pedersen.Commitment {
+	var amount ristretto.Scalar
+	amount.SetBigInt(x)
+
+	var blind ristretto.Scalar
+	blind.SetZero()
+
+	return pedersen.Commitment{
+		Commit:         pedersen.New(genData).BaseMult(&amount),
+		BlindingFactor: blind,
+	}
+}
+
+// publicCommitment returns the commitment without its blinding factor
+func publicCommitment(c pedersen.Commitment) pedersen.Commitment {
+	var blind ristretto.Scalar
+	blind.SetZero()
+
+	return pedersen.Commitment{
+		Commit:         c.Commit,
+		BlindingFactor: blind,
+	}
 }
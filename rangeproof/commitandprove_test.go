@@ -41,6 +41,23 @@ func TestCommitAddition(t *testing.T) {
 	require.Nil(t, errb)
 }
 
+func TestCommitWithBlinder(t *testing.T) {
+	key := []byte("wallet key")
+
+	// a commitment is recreated from the key, the index and the value
+	c := CommitWithBlinder(42, pedersen.DeriveBlinder(key, 3))
+	again := CommitWithBlinder(42, pedersen.DeriveBlinder(key, 3))
+	assert.True(t, c.PedersenCommitment.Equals(again.PedersenCommitment))
+	assert.True(t, VerifyCommit(42, c))
+
+	other := CommitWithBlinder(42, pedersen.DeriveBlinder(key, 4))
+	assert.False(t, c.PedersenCommitment.EqualValue(other.PedersenCommitment))
+
+	p, err := GenProof(42, again, 0, 100)
+	require.Nil(t, err)
+	assert.Nil(t, VerifyProof(p))
+}
+
 func TestCommitPositiveFlow(t *testing.T) {
 	fmt.Println("Testing valid commit construction.")
 	n := int64(42)
@@ -194,3 +211,140 @@ func TestNegativeFlow8(t *testing.T) {
 	require.NotNil(t, errp)
 	require.NotNil(t, errv)
 }
+
+func TestVerifyProofTamperedBulletproof(t *testing.T) {
+	p := genRangeProof(t, 42, 20, 100)
+	require.Nil(t, VerifyProof(p))
+
+	tampered := p
+	tampered.P.t.Rand()
+	assert.Equal(t, ErrInvalidProof, VerifyProof(tampered))
+
+	tampered = p
+	tampered.P.A.Rand()
+	assert.Equal(t, ErrInvalidProof, VerifyProof(tampered))
+
+	// a malformed bulletproof about the right commitments
+	tampered = p
+	tampered.P = Proof{N: N, V: p.P.V}
+	assert.Equal(t, ErrInvalidProof, VerifyProof(tampered))
+}
+
+func TestVerifyProofCommitmentMismatch(t *testing.T) {
+	p := genRangeProof(t, 42, 20, 100)
+
+	// a valid bulletproof for another commitment in the same range
+	other := genRangeProof(t, 50, 20, 100)
+	tampered := p
+	tampered.P = other.P
+	assert.Equal(t, ErrCommitmentMismatch, VerifyProof(tampered))
+
+	// the commitments in the wrong order
+	tampered = p
+	tampered.P.V = []pedersen.Commitment{p.P.V[1], p.P.V[0]}
+	assert.Equal(t, ErrCommitmentMismatch, VerifyProof(tampered))
+
+	// a single commitment
+	tampered = p
+	tampered.P.V = p.P.V[:1]
+	assert.Equal(t, ErrCommitmentMismatch, VerifyProof(tampered))
+
+	tampered = p
+	tampered.P.N = 32
+	assert.Equal(t, ErrCommitmentMismatch, VerifyProof(tampered))
+}
+
+func TestVerifyProofBounds(t *testing.T) {
+	p := genRangeProof(t, 42, 20, 100)
+
+	tampered := p
+	tampered.A = 10
+	assert.Equal(t, ErrLowerBound, VerifyProof(tampered))
+
+	tampered = p
+	tampered.B = 200
+	assert.Equal(t, ErrUpperBound, VerifyProof(tampered))
+
+	tampered = p
+	tampered.A, tampered.B = 100, 20
+	assert.Equal(t, ErrInvalidBounds, VerifyProof(tampered))
+
+	c, err := Commit(42)
+	require.Nil(t, err)
+	_, err = GenProof(42, c, 50, 50)
+	assert.Equal(t, ErrInvalidBounds, err)
+}
+
+func TestVerifyProofForgedBoundCommitment(t *testing.T) {
+	// 150 is not in [20, 100), but it is in [20, 200). A prover that
+	// could pick CB freely would prove the wider range and claim B = 100
+	v := int64(150)
+	c, err := Commit(v)
+	require.Nil(t, err)
+
+	p, err := GenProof(v, c, 20, 200)
+	require.Nil(t, err)
+	require.Nil(t, VerifyProof(p))
+
+	p.B = 100
+	assert.Equal(t, ErrUpperBound, VerifyProof(p))
+
+	// a blinded commitment to the right offset cannot be checked either
+	blinded := p
+	blinded.B = 200
+	c1, err := Commit(0)
+	require.Nil(t, err)
+	blinded.CB.PedersenCommitment = pedersen.Add(p.CB.PedersenCommitment, c1.PedersenCommitment)
+	blinded.CBpC.PedersenCommitment = pedersen.Add(p.CBpC.PedersenCommitment, c1.PedersenCommitment)
+	assert.Equal(t, ErrUpperBound, VerifyProof(blinded))
+}
+
+func TestVerifyProofNegativeBounds(t *testing.T) {
+	p := genRangeProof(t, -5, -10, 10)
+	assert.Nil(t, VerifyProof(p))
+
+	c, err := Commit(-11)
+	require.Nil(t, err)
+	_, err = GenProof(-11, c, -10, 10)
+	assert.NotNil(t, err)
+}
+
+// The interval of GenProof includes a and excludes b
+func TestGenProofBoundaries(t *testing.T) {
+	for _, v := range []int64{20, 99} {
+		p := genRangeProof(t, v, 20, 100)
+		assert.Nil(t, VerifyProof(p))
+	}
+
+	for _, v := range []int64{19, 100} {
+		c, err := Commit(v)
+		require.Nil(t, err)
+		_, err = GenProof(v, c, 20, 100)
+		assert.NotNil(t, err)
+	}
+
+	// the smallest interval holds a single value
+	p := genRangeProof(t, -7, -7, -6)
+	assert.Nil(t, VerifyProof(p))
+}
+
+// The result of GenProof is public, it must not carry the blinder of C
+func TestGenProofPublicOnly(t *testing.T) {
+	p := genRangeProof(t, 42, 20, 100)
+	require.Nil(t, VerifyProof(p))
+
+	public := []pedersen.Commitment{p.CA.PedersenCommitment, p.CApC.PedersenCommitment, p.CB.PedersenCommitment, p.CBpC.PedersenCommitment, p.CC.PedersenCommitment}
+	public = append(public, p.P.V...)
+	for _, c := range public {
+		assert.Equal(t, int32(0), c.BlindingFactor.IsNonZeroI())
+	}
+}
+
+func genRangeProof(t *testing.T, v, a, b int64) RangeProof {
+	c, err := Commit(v)
+	require.Nil(t, err)
+
+	p, err := GenProof(v, c, a, b)
+	require.Nil(t, err)
+	return p
+}
@@ -7,25 +7,24 @@ import (
 
 	ristretto "github.com/bwesterb/go-ristretto"
 	"github.com/vosbor/dusk-crypto/rangeproof/vector"
-	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
 )
 
 // Put all debug functions here
-func debugProve(x, y, z ristretto.Scalar, v, l, r []ristretto.Scalar, aL, aR, sL, sR []ristretto.Scalar) error {
+func debugProve(gens *generators, x, y, z ristretto.Scalar, v, l, r []ristretto.Scalar, aL, aR, sL, sR []ristretto.Scalar, N, M uint32) error {
 
-	ok, err := debugLxG(l, x, z, aL, aR, sL)
+	ok, err := debugLxG(gens, l, x, z, aL, aR, sL, N, M)
 	if !ok {
 		return errors.Wrap(err, "[DEBUG]: <l(x), G> is constructed incorrectly")
 	}
 
-	ok, err = debugRxHPrime(r, x, y, z, aR, sR)
+	ok, err = debugRxHPrime(gens, r, x, y, z, aR, sR, N, M)
 	if !ok {
 		return errors.Wrap(err, "[DEBUG]: <r(x), H'> is constructed incorrectly")
 	}
 
 	for i := range v {
 
-		ok = debugsizeOfV(v[i].BigInt())
+		ok = debugsizeOfV(v[i].BigInt(), N)
 		if !ok {
 			return errors.New("[DEBUG]: Value v is more than 2^N - 1")
 		}
@@ -36,20 +35,20 @@ func debugProve(x, y, z ristretto.Scalar, v, l, r []ristretto.Scalar, aL, aR, sL
 
 // DEBUG
 
-func debugT0(aL, aR []ristretto.Scalar, y, z ristretto.Scalar) (ristretto.Scalar, error) {
+func debugT0(aL, aR []ristretto.Scalar, y, z ristretto.Scalar, N, M uint32) (ristretto.Scalar, error) {
 
 	aLMinusZ := vector.SubScalar(aL, z)
 
 	aRPlusZ := vector.AddScalar(aR, z)
 
-	yNM := vector.ScalarPowers(y, uint32(N*M))
+	yNM := vector.ScalarPowers(y, N*M)
 
 	hada, err := vector.Hadamard(yNM, aRPlusZ)
 	if err != nil {
 		return ristretto.Scalar{}, err
 	}
 
-	zMTwoN := sumZMTwoN(z)
+	zMTwoN := sumZMTwoN(z, N, M)
 
 	rightIP, err := vector.Add(zMTwoN, hada)
 	if err != nil {
@@ -65,28 +64,24 @@ func debugT0(aL, aR []ristretto.Scalar, y, z ristretto.Scalar) (ristretto.Scalar
 }
 
 // <l(x), G> =  <aL, G> + x<sL, G> +<-z1, G>
-func debugLxG(l []ristretto.Scalar, x, z ristretto.Scalar, aL, aR, sL []ristretto.Scalar) (bool, error) {
+func debugLxG(gens *generators, l []ristretto.Scalar, x, z ristretto.Scalar, aL, aR, sL []ristretto.Scalar, N, M uint32) (bool, error) {
 
 	var P ristretto.Point
 	P.SetZero()
 
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((N * M)))
+	G := gens.ped.BaseVector.Bases
 
-	G := ped.BaseVector.Bases
-
-	lG, err := vector.Exp(l, G, N, M)
+	lG, err := vector.Exp(l, G, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<l(x), G>")
 	}
 	// <aL,G>
-	aLG, err := vector.Exp(aL, G, N, M)
+	aLG, err := vector.Exp(aL, G, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<aL,G>")
 	}
 	// x<sL, G>
-	sLG, err := vector.Exp(sL, G, N, M)
+	sLG, err := vector.Exp(sL, G, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "x<sL, G>")
 	}
@@ -96,7 +91,7 @@ func debugLxG(l []ristretto.Scalar, x, z ristretto.Scalar, aL, aR, sL []ristrett
 	// <-z1, G>
 	var zNeg ristretto.Scalar
 	zNeg.Neg(&z)
-	zNegG, err := vector.Exp(vector.FromScalar(zNeg, uint32(N*M)), G, N, M)
+	zNegG, err := vector.Exp(vector.FromScalar(zNeg, N*M), G, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<-z1, G>")
 	}
@@ -109,32 +104,25 @@ func debugLxG(l []ristretto.Scalar, x, z ristretto.Scalar, aL, aR, sL []ristrett
 }
 
 // < r(x), H'> = <aR, H> + x<sR, H> + <z*y^(n*m), H'> + sum( (< <z^(j+1),2^n>, H') ) from j = 1 to j = m
-func debugRxHPrime(r []ristretto.Scalar, x, y, z ristretto.Scalar, aR, sR []ristretto.Scalar) (bool, error) {
-
-	genData := []byte("vosbor.BulletProof.v1")
-
-	genData = append(genData, uint8(1))
-
-	ped2 := pedersen.New(genData)
-	ped2.BaseVector.Compute(uint32((N * M)))
+func debugRxHPrime(gens *generators, r []ristretto.Scalar, x, y, z ristretto.Scalar, aR, sR []ristretto.Scalar, N, M uint32) (bool, error) {
 
-	H := ped2.BaseVector.Bases
+	H := gens.ped2.BaseVector.Bases
 
 	Hprime := computeHprime(H, y)
 
 	// <r(x), H'>
-	rH, err := vector.Exp(r, Hprime, N, M)
+	rH, err := vector.Exp(r, Hprime, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<r(x), H'>")
 	}
 
 	// <aR,H>
-	aRH, err := vector.Exp(aR, H, N, M)
+	aRH, err := vector.Exp(aR, H, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<aR,H>")
 	}
 	// x<sR, H>
-	sRH, err := vector.Exp(sR, H, N, M)
+	sRH, err := vector.Exp(sR, H, int(N), int(M))
 	if err != nil {
 
 		return false, errors.Wrap(err, "x<sR, H>")
@@ -144,18 +132,18 @@ func debugRxHPrime(r []ristretto.Scalar, x, y, z ristretto.Scalar, aR, sR []rist
 	xsRH.ScalarMult(&sRH, &x)
 
 	// y^(n*m)
-	yNM := vector.ScalarPowers(y, uint32(N*M))
+	yNM := vector.ScalarPowers(y, N*M)
 
 	// z*y^nm
 	zMulYn := vector.MulScalar(yNM, z)
 
 	// p = <z*y^nm , H'>
-	p, err := vector.Exp(zMulYn, Hprime, N, M)
+	p, err := vector.Exp(zMulYn, Hprime, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "<z*y^nm , H'>")
 	}
 	// k = sum( (< <z^(j+1) * 2^n>, H') ) from j = 1 to j = m
-	k, err := vector.Exp(sumZMTwoN(z), Hprime, N, M)
+	k, err := vector.Exp(sumZMTwoN(z, N, M), Hprime, int(N), int(M))
 	if err != nil {
 		return false, errors.Wrap(err, "k = sum()...")
 	}
@@ -170,12 +158,12 @@ func debugRxHPrime(r []ristretto.Scalar, x, y, z ristretto.Scalar, aR, sR []rist
 	return rH.Equals(&rhs), nil
 }
 
-// debugsizeOfV returns true if v is less than 2^N - 1
-func debugsizeOfV(v *big.Int) bool {
+// debugsizeOfV returns true if v is at most 2^N - 1
+func debugsizeOfV(v *big.Int, N uint32) bool {
 	var twoN, e, one = big.NewInt(2), big.NewInt(int64(N)), big.NewInt(int64(1))
 	twoN.Exp(twoN, e, nil)
 	twoN.Sub(twoN, one)
 
 	cmp := v.Cmp(twoN)
-	return (cmp == -1)
+	return (cmp != 1)
 }
@@ -0,0 +1,86 @@
+package fiatshamir
+
+import (
+	"crypto/sha512"
+	"encoding"
+	"encoding/binary"
+	"hash"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Transcript is a Merlin-style Fiat-Shamir transcript.
+// Every message is absorbed together with a label and its length,
+// so that two different sequences of messages can never hash to the same state
+type Transcript struct {
+	h hash.Hash
+}
+
+// NewTranscript returns a transcript whose state is
+// separated from any other protocol by the given label
+func NewTranscript(label []byte) *Transcript {
+	t := &Transcript{h: sha512.New()}
+	t.AppendMessage([]byte("dom-sep"), label)
+	return t
+}
+
+// AppendMessage absorbs a labeled message into the transcript
+func (t *Transcript) AppendMessage(label, message []byte) {
+	var size [4]byte
+
+	binary.BigEndian.PutUint32(size[:], uint32(len(label)))
+	t.h.Write(size[:])
+	t.h.Write(label)
+
+	binary.BigEndian.PutUint32(size[:], uint32(len(message)))
+	t.h.Write(size[:])
+	t.h.Write(message)
+}
+
+// AppendPoint absorbs a labeled point into the transcript
+func (t *Transcript) AppendPoint(label []byte, p ristretto.Point) {
+	t.AppendMessage(label, p.Bytes())
+}
+
+// AppendScalar absorbs a labeled scalar into the transcript
+func (t *Transcript) AppendScalar(label []byte, s ristretto.Scalar) {
+	t.AppendMessage(label, s.Bytes())
+}
+
+// AppendUint64 absorbs a labeled integer into the transcript
+func (t *Transcript) AppendUint64(label []byte, n uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	t.AppendMessage(label, buf[:])
+}
+
+// ChallengeScalar derives a scalar from the current state of the transcript.
+// The challenge is absorbed back into the transcript, so that
+// consecutive challenges with the same label are distinct
+func (t *Transcript) ChallengeScalar(label []byte) ristretto.Scalar {
+	t.AppendMessage(label, nil)
+
+	var digest [64]byte
+	copy(digest[:], t.h.Sum(nil))
+
+	var s ristretto.Scalar
+	s.SetReduced(&digest)
+
+	t.AppendScalar(label, s)
+	return s
+}
+
+// Clone returns an independent copy of the transcript
+func (t *Transcript) Clone() *Transcript {
+	state, err := t.h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	h := sha512.New()
+	err = h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state)
+	if err != nil {
+		panic(err)
+	}
+	return &Transcript{h: h}
+}
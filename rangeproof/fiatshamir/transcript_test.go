@@ -0,0 +1,66 @@
+package fiatshamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptDeterministic(t *testing.T) {
+	a := NewTranscript([]byte("test"))
+	b := NewTranscript([]byte("test"))
+
+	a.AppendMessage([]byte("msg"), []byte("hello world"))
+	b.AppendMessage([]byte("msg"), []byte("hello world"))
+
+	ca := a.ChallengeScalar([]byte("c"))
+	cb := b.ChallengeScalar([]byte("c"))
+	assert.True(t, ca.Equals(&cb))
+
+	// consecutive challenges differ
+	ca2 := a.ChallengeScalar([]byte("c"))
+	assert.False(t, ca.Equals(&ca2))
+}
+
+func TestTranscriptDomainSeparation(t *testing.T) {
+
+	base := NewTranscript([]byte("test"))
+	base.AppendMessage([]byte("msg"), []byte("helloworld"))
+	expected := base.ChallengeScalar([]byte("c"))
+
+	// different protocol label
+	other := NewTranscript([]byte("other"))
+	other.AppendMessage([]byte("msg"), []byte("helloworld"))
+	c := other.ChallengeScalar([]byte("c"))
+	assert.False(t, expected.Equals(&c))
+
+	// same bytes split across messages
+	split := NewTranscript([]byte("test"))
+	split.AppendMessage([]byte("msg"), []byte("hello"))
+	split.AppendMessage(nil, []byte("world"))
+	c = split.ChallengeScalar([]byte("c"))
+	assert.False(t, expected.Equals(&c))
+
+	// bytes moved from the message into the label
+	moved := NewTranscript([]byte("test"))
+	moved.AppendMessage([]byte("msghello"), []byte("world"))
+	c = moved.ChallengeScalar([]byte("c"))
+	assert.False(t, expected.Equals(&c))
+}
+
+func TestTranscriptClone(t *testing.T) {
+	a := NewTranscript([]byte("test"))
+	a.AppendUint64([]byte("n"), 42)
+
+	b := a.Clone()
+	b.AppendMessage([]byte("extra"), []byte("data"))
+
+	c := a.Clone()
+
+	ca := a.ChallengeScalar([]byte("c"))
+	cb := b.ChallengeScalar([]byte("c"))
+	cc := c.ChallengeScalar([]byte("c"))
+
+	assert.False(t, ca.Equals(&cb))
+	assert.True(t, ca.Equals(&cc))
+}
@@ -1,20 +1,28 @@
 package generator
 
 import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+
 	ristretto "github.com/bwesterb/go-ristretto"
 )
 
 // This package will generate the generators for the pedersens and the bulletproof
 
 // Generator holds the information to generate a set of points
-// based on an initial byte slice; data
+// based on an initial byte slice; data.
+// Bases may be shared with other generators of the same data,
+// so they must not be modified
 type Generator struct {
-	data  []byte
-	Bases []ristretto.Point
+	data    []byte
+	indexed bool
+	Bases   []ristretto.Point
 }
 
 // New will generate a generator which
-// will use data to generate `n` points
+// will use data to generate `n` points.
+// Every point is derived from the previous one
 func New(data []byte) *Generator {
 	return &Generator{
 		data:  data,
@@ -22,6 +30,22 @@ func New(data []byte) *Generator {
 	}
 }
 
+// NewIndexed returns a generator whose point i is derived from
+// hash(label || i), so that points can be computed in parallel
+// or on their own
+func NewIndexed(label []byte) *Generator {
+	return &Generator{
+		data:    label,
+		indexed: true,
+		Bases:   []ristretto.Point{},
+	}
+}
+
+// Indexed returns true if the points are derived from their index
+func (g *Generator) Indexed() bool {
+	return g.indexed
+}
+
 //Clear will clear all of the Bases
 // but leave the counter as is
 func (g *Generator) Clear() {
@@ -30,28 +54,187 @@ func (g *Generator) Clear() {
 
 // Iterate will generate a new point using
 // the previous point's bytes as a seed or the original
-// nonce data, if no previous point is available
+// nonce data, if no previous point is available.
+// Indexed generators derive it from its index instead
 func (g *Generator) Iterate() ristretto.Point {
+	if g.indexed {
+		return IndexedPoint(g.data, uint32(len(g.Bases)))
+	}
+	return next(g.data, g.Bases)
+}
+
+// Compute will generate num amount of points, which will act as point generators
+// using the initial data.
+// The points of registered data come from the process wide cache, so
+// that they are only derived once, see Register
+func (g *Generator) Compute(num uint32) {
+	t := chained
+	if g.indexed {
+		t = indexed
+	}
+	g.Bases = t.compute(g.data, g.Bases, uint32(len(g.Bases))+num)
+}
+
+// table holds the points derived so far for the registered data.
+// A list of points is never modified once stored, growing it
+// stores a new one
+type table struct {
+	sync.RWMutex
+	points map[string][]ristretto.Point
+
+	// grow appends points to have until it holds n of them
+	grow func(data []byte, have []ristretto.Point, n uint32) []ristretto.Point
+}
+
+var (
+	chained = &table{points: make(map[string][]ristretto.Point), grow: growChained}
+	indexed = &table{points: make(map[string][]ristretto.Point), grow: growIndexed}
+)
+
+func (t *table) register(data []byte) {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, ok := t.points[string(data)]; !ok {
+		t.points[string(data)] = []ristretto.Point{}
+	}
+}
+
+func (t *table) registered(data []byte) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	_, ok := t.points[string(data)]
+	return ok
+}
+
+// extend returns have followed by the points up to n, leaving have untouched
+func (t *table) extend(data []byte, have []ristretto.Point, n uint32) []ristretto.Point {
+	grown := make([]ristretto.Point, len(have), n)
+	copy(grown, have)
+	return t.grow(data, grown, n)
+}
+
+// compute returns the first n points of data. Points of data which is
+// not registered are derived from the end of have instead of the cache
+func (t *table) compute(data []byte, have []ristretto.Point, n uint32) []ristretto.Point {
+	if t.registered(data) {
+		return t.get(data, n)
+	}
+	return t.extend(data, have, n)
+}
+
+func (t *table) get(data []byte, n uint32) []ristretto.Point {
+
+	t.RLock()
+	points, ok := t.points[string(data)]
+	t.RUnlock()
+
+	if !ok {
+		return t.extend(data, nil, n)
+	}
+	if uint32(len(points)) >= n {
+		return points[:n:n]
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// another caller may have grown the points in the meantime
+	points = t.points[string(data)]
+	if uint32(len(points)) >= n {
+		return points[:n:n]
+	}
+
+	grown := t.extend(data, points, n)
+	t.points[string(data)] = grown
+
+	return grown[:n:n]
+}
+
+// Register caches the points generated from data for the lifetime of
+// the process. Only registered data is cached, so that generators of
+// arbitrary data do not grow the cache; register the fixed seeds of a
+// protocol, never data that changes per call
+func Register(data []byte) {
+	chained.register(data)
+}
+
+// RegisterIndexed is like Register for indexed points of label
+func RegisterIndexed(label []byte) {
+	indexed.register(label)
+}
+
+// Cached returns the first n points generated from data. The points of
+// registered data are derived once per process and shared by every caller,
+// so they must not be modified. It is safe for concurrent use
+func Cached(data []byte, n uint32) []ristretto.Point {
+	return chained.get(data, n)
+}
+
+// CachedIndexed returns the first n indexed points of label. Like
+// Cached, the points of a registered label are shared and must not be
+// modified
+func CachedIndexed(label []byte, n uint32) []ristretto.Point {
+	return indexed.get(label, n)
+}
+
+// IndexedPoint derives point i of label, hash(label || i) with i
+// encoded as a big endian uint32
+func IndexedPoint(label []byte, i uint32) ristretto.Point {
+	buf := make([]byte, len(label)+4)
+	copy(buf, label)
+	binary.BigEndian.PutUint32(buf[len(label):], i)
+
+	var p ristretto.Point
+	p.Derive(buf)
+	return p
+}
+
+// next derives the point following bases
+func next(data []byte, bases []ristretto.Point) ristretto.Point {
 
 	p := ristretto.Point{}
 
-	if len(g.Bases) == 0 {
-		p.Derive(g.data)
+	if len(bases) == 0 {
+		p.Derive(data)
 		return p
 	}
 
-	prevPoint := g.Bases[len(g.Bases)-1]
+	prevPoint := bases[len(bases)-1]
 	p.Derive(prevPoint.Bytes())
 
 	return p
 }
 
-// Compute will generate num amount of points, which will act as point generators
-// using the initial data.
-func (g *Generator) Compute(num uint32) {
+func growChained(data []byte, have []ristretto.Point, n uint32) []ristretto.Point {
+	for uint32(len(have)) < n {
+		have = append(have, next(data, have))
+	}
+	return have
+}
+
+// growIndexed derives the missing points in parallel
+func growIndexed(label []byte, have []ristretto.Point, n uint32) []ristretto.Point {
+	start := uint32(len(have))
+	have = have[:n]
+
+	workers := uint32(runtime.NumCPU())
+	if missing := n - start; missing < workers {
+		workers = missing
+	}
 
-	for i := uint32(0); i < num; i++ {
-		g.Bases = append(g.Bases, g.Iterate())
+	var wg sync.WaitGroup
+	for w := uint32(0); w < workers; w++ {
+		wg.Add(1)
+		go func(w uint32) {
+			defer wg.Done()
+			for i := start + w; i < n; i += workers {
+				have[i] = IndexedPoint(label, i)
+			}
+		}(w)
 	}
+	wg.Wait()
 
+	return have
 }
@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"sync"
 	"testing"
 
 	ristretto "github.com/bwesterb/go-ristretto"
@@ -40,3 +41,151 @@ func TestGeneratorsClear(t *testing.T) {
 	assert.Equal(t, expected, actual)
 
 }
+
+func TestCached(t *testing.T) {
+
+	data := []byte("cached data")
+	generator.Register(data)
+
+	gens := generator.New(data)
+	for i := 0; i < 100; i++ {
+		gens.Bases = append(gens.Bases, gens.Iterate())
+	}
+
+	// shorter chains are a prefix of longer ones
+	assert.Equal(t, gens.Bases[:10], generator.Cached(data, 10))
+	assert.Equal(t, gens.Bases, generator.Cached(data, 100))
+	assert.Equal(t, gens.Bases[:50], generator.Cached(data, 50))
+	assert.Empty(t, generator.Cached(data, 0))
+
+	// Compute continues the chain from the cache
+	other := generator.New(data)
+	other.Compute(30)
+	other.Compute(70)
+	assert.Equal(t, gens.Bases, other.Bases)
+}
+
+func TestCachedIsolated(t *testing.T) {
+
+	data := []byte("isolated data")
+	generator.Register(data)
+
+	bases := generator.Cached(data, 10)
+	expected := append([]ristretto.Point{}, bases...)
+
+	// appending to a returned chain does not write into the cache
+	var point ristretto.Point
+	point.Rand()
+	_ = append(bases, point)
+
+	assert.Equal(t, expected, generator.Cached(data, 10))
+	assert.Equal(t, expected, generator.Cached(data, 11)[:10])
+	assert.NotEqual(t, point, generator.Cached(data, 11)[10])
+}
+
+func TestCachedConcurrent(t *testing.T) {
+
+	data := []byte("concurrent data")
+	generator.Register(data)
+
+	gens := generator.New(data)
+	for i := 0; i < 256; i++ {
+		gens.Bases = append(gens.Bases, gens.Iterate())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(n uint32) {
+			defer wg.Done()
+			assert.Equal(t, gens.Bases[:n], generator.Cached(data, n))
+		}(uint32(16 * (i + 1)))
+	}
+	wg.Wait()
+}
+
+func TestIndexed(t *testing.T) {
+
+	label := []byte("indexed label")
+	generator.RegisterIndexed(label)
+
+	gens := generator.NewIndexed(label)
+	assert.True(t, gens.Indexed())
+	for i := 0; i < 100; i++ {
+		gens.Bases = append(gens.Bases, gens.Iterate())
+	}
+
+	// every point can be computed on its own
+	for _, i := range []uint32{0, 1, 37, 99} {
+		assert.Equal(t, gens.Bases[i], generator.IndexedPoint(label, i))
+	}
+
+	// the parallel derivation matches the sequential one
+	assert.Equal(t, gens.Bases[:10], generator.CachedIndexed(label, 10))
+	assert.Equal(t, gens.Bases, generator.CachedIndexed(label, 100))
+
+	other := generator.NewIndexed(label)
+	other.Compute(30)
+	other.Compute(70)
+	assert.Equal(t, gens.Bases, other.Bases)
+
+	// the two modes give different points for the same data
+	assert.NotEqual(t, generator.Cached(label, 10), generator.CachedIndexed(label, 10))
+	assert.False(t, generator.New(label).Indexed())
+}
+
+func TestIndexedConcurrent(t *testing.T) {
+
+	label := []byte("concurrent label")
+	generator.RegisterIndexed(label)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(n uint32) {
+			defer wg.Done()
+			bases := generator.CachedIndexed(label, n)
+			for j := range bases {
+				assert.Equal(t, generator.IndexedPoint(label, uint32(j)), bases[j])
+			}
+		}(uint32(16 * (i + 1)))
+	}
+	wg.Wait()
+}
+
+func TestUnregisteredNotCached(t *testing.T) {
+
+	registered := []byte("registered data")
+	generator.Register(registered)
+	generator.Cached(registered, 16)
+
+	// registered points are only derived once
+	allocs := testing.AllocsPerRun(10, func() {
+		generator.Cached(registered, 16)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	// other data gives the same points, derived on every call
+	unregistered := []byte("unregistered data")
+	gens := generator.New(unregistered)
+	for i := 0; i < 16; i++ {
+		gens.Bases = append(gens.Bases, gens.Iterate())
+	}
+	assert.Equal(t, gens.Bases, generator.Cached(unregistered, 16))
+
+	allocs = testing.AllocsPerRun(10, func() {
+		generator.Cached(unregistered, 16)
+	})
+	assert.NotEqual(t, float64(0), allocs)
+
+	// Compute continues its own points
+	other := generator.New(unregistered)
+	other.Compute(10)
+	other.Compute(6)
+	assert.Equal(t, gens.Bases, other.Bases)
+
+	indexedGens := generator.NewIndexed(unregistered)
+	indexedGens.Compute(10)
+	indexedGens.Compute(6)
+	assert.Equal(t, generator.CachedIndexed(unregistered, 16), indexedGens.Bases)
+}
@@ -9,6 +9,7 @@ import (
 
 	ristretto "github.com/bwesterb/go-ristretto"
 	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
 	"github.com/vosbor/dusk-crypto/rangeproof/vector"
 )
 
@@ -47,6 +48,12 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 	Lj := make([]ristretto.Point, 0, lgN)
 	Rj := make([]ristretto.Point, 0, lgN)
 
+	// scratch space for the commitments of every round
+	buf := &lrBuffer{
+		scalars: make([]ristretto.Scalar, 0, n+1),
+		points:  make([]ristretto.Point, 0, n+1),
+	}
+
 	if n != 1 {
 		n = n / 2
 
@@ -64,62 +71,20 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			return nil, err
 		}
 
-		// L = aL * GR + bR * HL * HPrime[0..n] + cL * Q = e1 + e2 + e3
+		// L = aL * GR + bR * HL * HPrime[0..n] + cL * Q
 
-		e1, err := vector.Exp(aL, GR, int(n), 1)
+		L, err := buf.commit(aL, bR, HprimeFactors[:n], cL, GR, HL, Q)
 		if err != nil {
 			return nil, err
-
-		}
-
-		bRYi := make([]ristretto.Scalar, len(bR))
-		copy(bRYi, bR)
-
-		for i := range bRYi {
-			bRYi[i].Mul(&bRYi[i], &HprimeFactors[i])
 		}
-
-		e2, err := vector.Exp(bRYi, HL, int(n), 1)
-		if err != nil {
-			return nil, err
-		}
-
-		var e3 ristretto.Point
-		e3.ScalarMult(&Q, &cL)
-
-		var L ristretto.Point
-		L.SetZero()
-		L.Add(&e1, &e2)
-		L.Add(&L, &e3)
-
 		Lj = append(Lj, L)
 
-		// R = aR * GL + bL * HR * HPrimeFactors[n .. 2n] + cR * Q = e4 + e5 + e6
-
-		e4, err := vector.Exp(aR, GL, int(n), 1)
-		if err != nil {
-			return nil, err
-		}
-
-		bLYi := make([]ristretto.Scalar, len(bL))
-		copy(bLYi, bL)
+		// R = aR * GL + bL * HR * HPrimeFactors[n .. 2n] + cR * Q
 
-		for i := range bLYi {
-			bLYi[i].Mul(&bLYi[i], &HprimeFactors[uint32(i)+n])
-		}
-
-		e5, err := vector.Exp(bLYi, HR, int(n), 1)
+		R, err := buf.commit(aR, bL, HprimeFactors[n:2*n], cR, GL, HR, Q)
 		if err != nil {
 			return nil, err
 		}
-
-		var e6 ristretto.Point
-		e6.ScalarMult(&Q, &cR)
-
-		var R ristretto.Point
-		R.SetZero()
-		R.Add(&e4, &e5)
-		R.Add(&R, &e6)
 		Rj = append(Rj, R)
 
 		hs.Append(L.Bytes(), R.Bytes())
@@ -129,7 +94,6 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 		uinv.Inverse(&u)
 
 		var a1, a2, b1, b2, h1a, h2a ristretto.Scalar
-		var g1, g2, h1, h2 ristretto.Point
 
 		for i := uint32(0); i < n; i++ {
 
@@ -141,15 +105,11 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			b2.Mul(&bR[i], &u)
 			bL[i].Add(&b1, &b2)
 
-			g1.ScalarMult(&GL[i], &uinv)
-			g2.ScalarMult(&GR[i], &u)
-			GL[i].Add(&g1, &g2)
+			GL[i] = fold(GL[i], GR[i], uinv, u)
 
 			h1a.Mul(&HprimeFactors[i], &u)
-			h1.ScalarMult(&HL[i], &h1a)
 			h2a.Mul(&HprimeFactors[i+n], &uinv)
-			h2.ScalarMult(&HR[i], &h2a)
-			HL[i].Add(&h1, &h2)
+			HL[i] = fold(HL[i], HR[i], h1a, h2a)
 		}
 
 		a = aL
@@ -176,43 +136,20 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			return nil, err
 		}
 
-		// L = aL * GR + bR * HL + cL * Q = e1 + e2 + e3
+		// L = aL * GR + bR * HL + cL * Q
 
-		e1, err := vector.Exp(aL, GR, int(n), 1)
-		if err != nil {
-			return nil, err
-		}
-		e2, err := vector.Exp(bR, HL, int(n), 1)
+		L, err := buf.commit(aL, bR, nil, cL, GR, HL, Q)
 		if err != nil {
 			return nil, err
 		}
-		var e3 ristretto.Point
-		e3.ScalarMult(&Q, &cL)
-
-		var L ristretto.Point
-		L.SetZero()
-		L.Add(&e1, &e2)
-		L.Add(&L, &e3)
-
 		Lj = append(Lj, L)
 
-		// R = aR * GL + bL * HR + cR * Q = e4 + e5 + e6
+		// R = aR * GL + bL * HR + cR * Q
 
-		e4, err := vector.Exp(aR, GL, int(n), 1)
+		R, err := buf.commit(aR, bL, nil, cR, GL, HR, Q)
 		if err != nil {
 			return nil, err
 		}
-		e5, err := vector.Exp(bL, HR, int(n), 1)
-		if err != nil {
-			return nil, err
-		}
-		var e6 ristretto.Point
-		e6.ScalarMult(&Q, &cR)
-
-		var R ristretto.Point
-		R.SetZero()
-		R.Add(&e4, &e5)
-		R.Add(&R, &e6)
 		Rj = append(Rj, R)
 
 		hs.Append(L.Bytes(), R.Bytes())
@@ -227,7 +164,6 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 		// HL = HL * u + HR * uinv = h1 + h2 - hprime
 
 		var a1, a2, b1, b2 ristretto.Scalar
-		var g1, g2, h1, h2 ristretto.Point
 
 		for i := uint32(0); i < n; i++ {
 
@@ -239,13 +175,8 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			b2.Mul(&bR[i], &u)
 			bL[i].Add(&b1, &b2)
 
-			g1.ScalarMult(&GL[i], &uinv)
-			g2.ScalarMult(&GR[i], &u)
-			GL[i].Add(&g1, &g2)
-
-			h1.ScalarMult(&HL[i], &u)
-			h2.ScalarMult(&HR[i], &uinv)
-			HL[i].Add(&h1, &h2)
+			GL[i] = fold(GL[i], GR[i], uinv, u)
+			HL[i] = fold(HL[i], HR[i], u, uinv)
 		}
 
 		a = aL
@@ -262,6 +193,42 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 	}, nil
 }
 
+// lrBuffer holds the terms of the L and R commitments, so that
+// every round of the prover reuses the same memory
+type lrBuffer struct {
+	scalars []ristretto.Scalar
+	points  []ristretto.Point
+}
+
+// commit returns a * G + (b Had bFactors) * H + c * Q, with bFactors
+// treated as ones when nil. The scalars are secret, so it runs in
+// constant time
+func (buf *lrBuffer) commit(a, b, bFactors []ristretto.Scalar, c ristretto.Scalar, G, H []ristretto.Point, Q ristretto.Point) (ristretto.Point, error) {
+	buf.scalars = append(buf.scalars[:0], a...)
+	buf.scalars = append(buf.scalars, b...)
+	if bFactors != nil {
+		if len(bFactors) != len(b) {
+			return ristretto.Point{}, errors.New("[IPProof]: length of b does not equal length of its factors")
+		}
+		bs := buf.scalars[len(a):]
+		for i := range bs {
+			bs[i].Mul(&bs[i], &bFactors[i])
+		}
+	}
+	buf.scalars = append(buf.scalars, c)
+
+	buf.points = append(buf.points[:0], G...)
+	buf.points = append(buf.points, H...)
+	buf.points = append(buf.points, Q)
+
+	return vector.Exp(buf.scalars, buf.points, len(buf.points), 1)
+}
+
+// fold returns x * P + y * Q for the public challenges x and y
+func fold(P, Q ristretto.Point, x, y ristretto.Scalar) ristretto.Point {
+	return msm.VarTimeDoubleScalarMult(&x, &P, &y, &Q)
+}
+
 // VerifScalars generates the challenge squared, the inverse challenge squared
 // and s for a given inner product proof
 func (proof *Proof) VerifScalars() ([]ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar) {
@@ -383,7 +350,12 @@ func (proof *Proof) Verify(G, H, L, R []ristretto.Point, HprimeFactor []ristrett
 	points = append(points, proof.L...)
 	points = append(points, proof.R...)
 
-	have, err := vector.Exp(scalars, points, n, 1)
+	if len(scalars) < n {
+		return false
+	}
+
+	// everything is public, so the variable time engine is safe here
+	have, err := msm.VarTimeMultiScalarMult(scalars, points)
 	if err != nil {
 		return false
 	}
@@ -416,12 +388,47 @@ func (proof *Proof) Encode(w io.Writer) error {
 	return nil
 }
 
-// Decode a Proof
+// Decode a Proof, reading L and R pairs up to the end of r. When the
+// number of rounds of the proof is known, DecodeRounds should be used
+// instead, as it stops after the proof and bounds what it reads
 func (proof *Proof) Decode(r io.Reader) error {
 	if proof == nil {
 		return errors.New("struct is nil")
 	}
 
+	if err := proof.decodeScalars(r); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+	if buf.Len()%64 != 0 {
+		return errors.New("proof was not formatted correctly")
+	}
+	return proof.decodePairs(buf, uint32(buf.Len()/64))
+}
+
+// DecodeRounds decodes a Proof of exactly rounds L and R pairs, which is
+// lg(n) for vectors of size n, and leaves what follows it in r unread
+func (proof *Proof) DecodeRounds(r io.Reader, rounds uint32) error {
+	if proof == nil {
+		return errors.New("struct is nil")
+	}
+	if rounds > 32 {
+		return errors.New("proof has too many rounds")
+	}
+
+	if err := proof.decodeScalars(r); err != nil {
+		return err
+	}
+	return proof.decodePairs(r, rounds)
+}
+
+// decodeScalars reads a and b
+func (proof *Proof) decodeScalars(r io.Reader) error {
 	var ABytes, BBytes [32]byte
 	err := binary.Read(r, binary.BigEndian, &ABytes)
 	if err != nil {
@@ -433,35 +440,35 @@ func (proof *Proof) Decode(r io.Reader) error {
 	}
 	proof.A.SetBytes(&ABytes)
 	proof.B.SetBytes(&BBytes)
+	return nil
+}
 
-	buf := &bytes.Buffer{}
-	_, err = buf.ReadFrom(r)
-	if err != nil {
-		return err
-	}
-	numBytes := len(buf.Bytes())
-	if numBytes%32 != 0 {
-		return errors.New("proof was not formatted correctly")
-	}
-	lenL := uint32(numBytes / 64)
-
-	proof.L = make([]ristretto.Point, lenL)
-	proof.R = make([]ristretto.Point, lenL)
+// decodePairs reads rounds L and R pairs
+func (proof *Proof) decodePairs(r io.Reader, rounds uint32) error {
+	proof.L = make([]ristretto.Point, rounds)
+	proof.R = make([]ristretto.Point, rounds)
 
-	for i := uint32(0); i < lenL; i++ {
-		var LBytes, RBytes [32]byte
-		err = binary.Read(buf, binary.BigEndian, &LBytes)
-		if err != nil {
+	for i := uint32(0); i < rounds; i++ {
+		if err := readPoint(r, &proof.L[i]); err != nil {
 			return err
 		}
-		err = binary.Read(buf, binary.BigEndian, &RBytes)
-		if err != nil {
+		if err := readPoint(r, &proof.R[i]); err != nil {
 			return err
 		}
-		proof.L[i].SetBytes(&LBytes)
-		proof.R[i].SetBytes(&RBytes)
 	}
+	return nil
+}
 
+// readPoint reads a point and rejects invalid encodings
+func readPoint(r io.Reader, p *ristretto.Point) error {
+	var pBytes [32]byte
+	err := binary.Read(r, binary.BigEndian, &pBytes)
+	if err != nil {
+		return err
+	}
+	if !p.SetBytes(&pBytes) {
+		return errors.New("point not encodable")
+	}
 	return nil
 }
 
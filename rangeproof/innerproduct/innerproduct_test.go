@@ -30,7 +30,7 @@ func TestProofCreation(t *testing.T) {
 		assert.Equal(t, nil, err)
 
 		var decodedProof Proof
-		decodedProof.Decode(buf)
+		err = decodedProof.Decode(buf)
 		assert.Equal(t, nil, err)
 		ok = proof.Equals(decodedProof)
 		assert.True(t, ok)
@@ -40,6 +40,37 @@ func TestProofCreation(t *testing.T) {
 
 }
 
+func TestDecodeRounds(t *testing.T) {
+	_, G, H, Hpf, a, b, Q := testHelpCreate(16, t)
+
+	proof, err := Generate(G, H, a, b, Hpf, Q)
+	assert.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, proof.Encode(buf))
+	encoded := buf.Bytes()
+
+	// the bytes that follow the proof are left unread
+	stream := bytes.NewBuffer(append(append([]byte{}, encoded...), 1, 2, 3))
+	var decodedProof Proof
+	assert.Nil(t, decodedProof.DecodeRounds(stream, 4))
+	assert.True(t, proof.Equals(decodedProof))
+	assert.Equal(t, []byte{1, 2, 3}, stream.Bytes())
+
+	// too few pairs
+	assert.NotNil(t, decodedProof.DecodeRounds(bytes.NewReader(encoded), 5))
+	assert.NotNil(t, decodedProof.DecodeRounds(bytes.NewReader(encoded), 33))
+
+	// an invalid point
+	invalid := append([]byte{}, encoded...)
+	copy(invalid[64:96], bytes.Repeat([]byte{0xFF}, 32))
+	assert.NotNil(t, decodedProof.DecodeRounds(bytes.NewReader(invalid), 4))
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(invalid)))
+
+	// half a pair
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded[:len(encoded)-32])))
+}
+
 // given an n returns P, G,H,HprimeFactors a, b, Q
 func testHelpCreate(n uint32, t *testing.T) (ristretto.Point, []ristretto.Point, []ristretto.Point, []ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar, ristretto.Point) {
 	a := randomScalarArr(n)
@@ -0,0 +1,195 @@
+package rangeproof
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+var (
+	// ErrEmptyInterval is returned when the lower bound is greater than the upper bound
+	ErrEmptyInterval = errors.New("lower bound cannot be greater than the upper bound")
+	// ErrNotInInterval is returned when the value to prove is outside of the interval
+	ErrNotInInterval = errors.New("value is not in the interval")
+)
+
+// IntervalProof shows that a commitment opens to a value in [a, b]. It
+// only holds public data, the commitments it is about are recomputed by
+// the verifier from the commitment and the bounds
+type IntervalProof struct {
+	p Proof
+}
+
+// ProveInterval proves that the commitment v * H + blinder * G opens to
+// a value in [a, b]. Both bounds are included, so that any int64 can be
+// proven to lie in [math.MinInt64, math.MaxInt64]
+//
+// The proof aggregates v - a and b - v, which both lie in [0, 2^n) for the
+// smallest supported n with b - a < 2^n, so narrow intervals get smaller
+// proofs
+func ProveInterval(v int64, blinder ristretto.Scalar, a, b int64) (*IntervalProof, error) {
+
+	if a > b {
+		return nil, ErrEmptyInterval
+	}
+	if v < a || v > b {
+		return nil, ErrNotInInterval
+	}
+
+	n := intervalBits(a, b)
+
+	// v - a and b - v as integers, they do not fit in an int64 for wide intervals
+	bigV := big.NewInt(v)
+	lower := new(big.Int).Sub(bigV, big.NewInt(a))
+	upper := new(big.Int).Sub(big.NewInt(b), bigV)
+
+	// C - a * H opens to v - a with blinder r, b * H - C to b - v with -r
+	openings := make([]Opening, 2)
+	openings[0].Value.SetBigInt(lower)
+	openings[0].Blinder = blinder
+	openings[1].Value.SetBigInt(upper)
+	openings[1].Blinder.Neg(&blinder)
+
+	p, err := ProveBits(n, openings, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// only keep what the verifier cannot recompute
+	p.V = nil
+	return &IntervalProof{p: p}, nil
+}
+
+// VerifyInterval checks that commit opens to a value in [a, b]. It
+// returns nil if the proof is valid, ErrEmptyInterval for invalid bounds
+// and ErrInvalidProof otherwise
+func VerifyInterval(commit ristretto.Point, a, b int64, proof *IntervalProof) (err error) {
+
+	if a > b {
+		return ErrEmptyInterval
+	}
+	if proof == nil {
+		return ErrInvalidProof
+	}
+
+	// the proof must be of the width implied by the bounds
+	p := proof.p
+	if p.N != intervalBits(a, b) || p.M != 2 {
+		return ErrInvalidProof
+	}
+
+	lowerCommit, upperCommit := intervalCommitments(commit, a, b)
+	p.V = []pedersen.Commitment{lowerCommit, upperCommit}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrInvalidProof
+		}
+	}()
+	if ok, err := Verify(p); !ok || err != nil {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// maxIntervalProofSize is the length of the encoding of an interval proof
+// of 64 bits: a header of 5 bytes, 4 points and 3 scalars, the scalars a
+// and b of the inner product proof and its 7 pairs of L and R
+const maxIntervalProofSize = 5 + 7*32 + 2*32 + 7*64
+
+// Encode writes the length of the proof as a uint32 followed by the
+// proof, so that the proof can be followed by other data in a stream
+func (proof *IntervalProof) Encode(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	if err := proof.p.Encode(buf, false); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(buf.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Decode reads a proof written by Encode
+func (proof *IntervalProof) Decode(r io.Reader) error {
+	if proof == nil {
+		return errors.New("struct is nil")
+	}
+
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return err
+	}
+	if length > maxIntervalProofSize {
+		return fmt.Errorf("interval proof of %d bytes exceeds the maximum of %d", length, maxIntervalProofSize)
+	}
+	encoded := make([]byte, length)
+	if _, err := io.ReadFull(r, encoded); err != nil {
+		return err
+	}
+
+	// an interval proof is over at most two 64 bit values
+	var p Proof
+	rd := bytes.NewReader(encoded)
+	if err := p.DecodeLimited(rd, false, 2*64); err != nil {
+		return err
+	}
+	if p.M != 2 {
+		return errors.New("interval proofs aggregate two values")
+	}
+	if rd.Len() != 0 {
+		return errors.New("interval proof is shorter than its length")
+	}
+	proof.p = p
+	return nil
+}
+
+// Equals returns true if both proofs are the same
+func (proof *IntervalProof) Equals(other IntervalProof) bool {
+	if !proof.p.Equals(other.p, false) {
+		return false
+	}
+	if proof.p.IPProof == nil || other.p.IPProof == nil {
+		return proof.p.IPProof == other.p.IPProof
+	}
+	return proof.p.IPProof.Equals(*other.p.IPProof)
+}
+
+// intervalBits returns the smallest supported bit width n with b - a < 2^n
+func intervalBits(a, b int64) uint32 {
+	width := new(big.Int).Sub(big.NewInt(b), big.NewInt(a))
+	for _, n := range []uint32{8, 16, 32} {
+		if width.BitLen() <= int(n) {
+			return n
+		}
+	}
+	return 64
+}
+
+// intervalCommitments returns C - a * H and b * H - C
+func intervalCommitments(commit ristretto.Point, a, b int64) (pedersen.Commitment, pedersen.Commitment) {
+	ped := pedersen.New(genData)
+
+	aH := ped.BaseMult(scalarFromInt64(a))
+	bH := ped.BaseMult(scalarFromInt64(b))
+
+	var lower, upper pedersen.Commitment
+	lower.Commit.Sub(&commit, &aH)
+	upper.Commit.Sub(&bH, &commit)
+	return lower, upper
+}
+
+// scalarFromInt64 maps x to a scalar, negative values to l - |x|
+func scalarFromInt64(x int64) *ristretto.Scalar {
+	var s ristretto.Scalar
+	s.SetBigInt(big.NewInt(x))
+	return &s
+}
@@ -0,0 +1,177 @@
+package rangeproof
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+func TestProveInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		v, a, b int64
+		bits    uint32
+	}{
+		{"inside", 42, 20, 100, 8},
+		{"lower bound", 20, 20, 100, 8},
+		{"upper bound", 100, 20, 100, 8},
+		{"single value", 7, 7, 7, 8},
+		{"negative", -5, -10, 10, 8},
+		{"negative bounds", -150, -200, -100, 8},
+		{"16 bits", 1000, 0, 60000, 16},
+		{"32 bits", 1 << 20, -(1 << 30), 1 << 30, 32},
+		{"full range min", math.MinInt64, math.MinInt64, math.MaxInt64, 64},
+		{"full range max", math.MaxInt64, math.MinInt64, math.MaxInt64, 64},
+		{"full range zero", 0, math.MinInt64, math.MaxInt64, 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, blinder := intervalCommit(tt.v)
+
+			proof, err := ProveInterval(tt.v, blinder, tt.a, tt.b)
+			require.Nil(t, err)
+			assert.Equal(t, tt.bits, proof.p.N)
+			assert.Nil(t, VerifyInterval(commit, tt.a, tt.b, proof))
+
+			// the encoding carries no commitment and no blinder
+			buf := &bytes.Buffer{}
+			require.Nil(t, proof.Encode(buf))
+			assert.NotContains(t, string(buf.Bytes()), string(blinder.Bytes()))
+			assert.NotContains(t, string(buf.Bytes()), string(commit.Bytes()))
+
+			var decoded IntervalProof
+			require.Nil(t, decoded.Decode(buf))
+			assert.True(t, proof.Equals(decoded))
+			assert.Nil(t, VerifyInterval(commit, tt.a, tt.b, &decoded))
+		})
+	}
+}
+
+func TestProveIntervalOutside(t *testing.T) {
+	_, blinder := intervalCommit(0)
+
+	_, err := ProveInterval(19, blinder, 20, 100)
+	assert.Equal(t, ErrNotInInterval, err)
+
+	_, err = ProveInterval(101, blinder, 20, 100)
+	assert.Equal(t, ErrNotInInterval, err)
+
+	_, err = ProveInterval(50, blinder, 100, 20)
+	assert.Equal(t, ErrEmptyInterval, err)
+}
+
+func TestVerifyIntervalInvalid(t *testing.T) {
+	commit, blinder := intervalCommit(42)
+
+	proof, err := ProveInterval(42, blinder, 20, 100)
+	require.Nil(t, err)
+
+	// another commitment to the same value
+	other, _ := intervalCommit(42)
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(other, 20, 100, proof))
+
+	// other bounds of the same width
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 21, 101, proof))
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 20, 99, proof))
+
+	// bounds of another width
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 20, 1000, proof))
+
+	assert.Equal(t, ErrEmptyInterval, VerifyInterval(commit, 100, 20, proof))
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 20, 100, nil))
+
+	tampered := *proof
+	tampered.p.t.Rand()
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 20, 100, &tampered))
+
+	tampered = *proof
+	tampered.p.IPProof = nil
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(commit, 20, 100, &tampered))
+
+	// a proof about a value outside of the interval, made for a wider one
+	outside, blinder := intervalCommit(150)
+	proof, err = ProveInterval(150, blinder, 20, 200)
+	require.Nil(t, err)
+	assert.Nil(t, VerifyInterval(outside, 20, 200, proof))
+	assert.Equal(t, ErrInvalidProof, VerifyInterval(outside, 20, 100, proof))
+}
+
+func TestIntervalProofDecodeInvalid(t *testing.T) {
+	_, blinder := intervalCommit(42)
+
+	// proofs aggregating more than two values are rejected
+	openings := randomValues(4)
+	p, err := Prove(openings, false)
+	require.Nil(t, err)
+
+	encoded := &bytes.Buffer{}
+	require.Nil(t, p.Encode(encoded, false))
+	buf := &bytes.Buffer{}
+	require.Nil(t, binary.Write(buf, binary.BigEndian, uint32(encoded.Len())))
+	buf.Write(encoded.Bytes())
+	var decoded IntervalProof
+	assert.NotNil(t, decoded.Decode(buf))
+
+	proof, err := ProveInterval(42, blinder, 20, 100)
+	require.Nil(t, err)
+	buf.Reset()
+	require.Nil(t, proof.Encode(buf))
+	valid := buf.Bytes()
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(valid[:10])))
+
+	// lengths beyond the largest interval proof are rejected before reading
+	tampered := append([]byte{}, valid...)
+	binary.BigEndian.PutUint32(tampered, maxIntervalProofSize+1)
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(tampered)))
+
+	// a length that does not match the proof
+	binary.BigEndian.PutUint32(tampered, uint32(len(valid)-4+1))
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(append(tampered, 0))))
+	binary.BigEndian.PutUint32(tampered, uint32(len(valid)-4-1))
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(tampered)))
+}
+
+// Interval proofs carry their length, so that they can be embedded
+// in a stream
+func TestIntervalProofStream(t *testing.T) {
+	commit1, blinder1 := intervalCommit(42)
+	commit2, blinder2 := intervalCommit(-7)
+
+	first, err := ProveInterval(42, blinder1, 20, 100)
+	require.Nil(t, err)
+	second, err := ProveInterval(-7, blinder2, math.MinInt64, 0)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, first.Encode(buf))
+	require.Nil(t, second.Encode(buf))
+	buf.WriteString("trailing")
+
+	var decoded IntervalProof
+	require.Nil(t, decoded.Decode(buf))
+	assert.Nil(t, VerifyInterval(commit1, 20, 100, &decoded))
+
+	require.Nil(t, decoded.Decode(buf))
+	assert.Nil(t, VerifyInterval(commit2, math.MinInt64, 0, &decoded))
+
+	assert.Equal(t, "trailing", buf.String())
+}
+
+// intervalCommit returns a commitment to v and its blinder
+func intervalCommit(v int64) (ristretto.Point, ristretto.Scalar) {
+	var blinder ristretto.Scalar
+	blinder.Rand()
+
+	ped := pedersen.New(genData)
+	commit := ped.BaseMult(scalarFromInt64(v))
+	rG := ped.BlindMult(&blinder)
+	commit.Add(&commit, &rG)
+	return commit, blinder
+}
@@ -0,0 +1,106 @@
+// Package msm computes multiscalar multiplications sum(s_i * P_i) over ristretto.
+//
+// Straus' method shares the doublings between all points and suits small
+// and medium sizes. It is constant time, and is the method to use whenever
+// a scalar is secret. Pippenger's bucket method wins for large sizes, but
+// its buckets are indexed by the digits of the scalars, so it is only
+// offered in variable time, for public data such as a verifier's equation.
+package msm
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// pippengerThreshold is the size from which Pippenger beats Straus
+const pippengerThreshold = 190
+
+// MultiScalarMult returns sum(scalars[i] * points[i]) in constant time
+func MultiScalarMult(scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	if len(scalars) != len(points) {
+		return ristretto.Point{}, errors.New("number of scalars and points do not match")
+	}
+	return straus(scalars, points), nil
+}
+
+// VarTimeMultiScalarMult returns sum(scalars[i] * points[i]), picking the
+// fastest method for the size. It leaks the scalars through timing and
+// must only be used on public data
+func VarTimeMultiScalarMult(scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	if len(scalars) != len(points) {
+		return ristretto.Point{}, errors.New("number of scalars and points do not match")
+	}
+	if len(points) < pippengerThreshold {
+		return varTimeStraus(scalars, points), nil
+	}
+	return varTimePippenger(scalars, points), nil
+}
+
+// Straus returns sum(scalars[i] * points[i]) with Straus' method, in constant time
+func Straus(scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	if len(scalars) != len(points) {
+		return ristretto.Point{}, errors.New("number of scalars and points do not match")
+	}
+	return straus(scalars, points), nil
+}
+
+// VarTimeStraus returns sum(scalars[i] * points[i]) with Straus' method, in variable time
+func VarTimeStraus(scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	if len(scalars) != len(points) {
+		return ristretto.Point{}, errors.New("number of scalars and points do not match")
+	}
+	return varTimeStraus(scalars, points), nil
+}
+
+// VarTimePippenger returns sum(scalars[i] * points[i]) with Pippenger's method, in variable time
+func VarTimePippenger(scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	if len(scalars) != len(points) {
+		return ristretto.Point{}, errors.New("number of scalars and points do not match")
+	}
+	return varTimePippenger(scalars, points), nil
+}
+
+// signedDigits writes s in radix 2^w with digits in [-2^(w-1), 2^(w-1)].
+// Scalars are below 2^253, so the last digit absorbs the final carry
+func signedDigits(s *ristretto.Scalar, w uint, digits []int32) {
+	var buf [32]byte
+	s.BytesInto(&buf)
+
+	// little endian 64 bit limbs, with a spare limb for windows
+	// reading past the end of the scalar
+	var limbs [5]uint64
+	for i := 0; i < 32; i++ {
+		limbs[i/8] |= uint64(buf[i]) << (8 * uint(i%8))
+	}
+
+	radix := int32(1) << w
+	half := radix >> 1
+	mask := uint64(radix - 1)
+
+	carry := int32(0)
+	for i := range digits {
+		pos := uint(i) * w
+		limb, shift := pos/64, pos%64
+
+		bits := limbs[limb] >> shift
+		if shift+w > 64 {
+			bits |= limbs[limb+1] << (64 - shift)
+		}
+
+		digit := int32(bits&mask) + carry
+		carry = (digit + half) >> w
+		digits[i] = digit - carry<<w
+	}
+	digits[len(digits)-1] += carry << w
+}
+
+// numDigits returns the number of radix 2^w digits of a scalar
+func numDigits(w uint) int {
+	return int((253+w-1)/w) + 1
+}
+
+func ext(p *ristretto.Point) *edwards25519.ExtendedPoint {
+	return (*edwards25519.ExtendedPoint)(p)
+}
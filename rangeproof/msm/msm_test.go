@@ -0,0 +1,172 @@
+package msm
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type msmFunc func([]ristretto.Scalar, []ristretto.Point) (ristretto.Point, error)
+
+var methods = map[string]msmFunc{
+	"MultiScalarMult":        MultiScalarMult,
+	"VarTimeMultiScalarMult": VarTimeMultiScalarMult,
+	"Straus":                 Straus,
+	"VarTimeStraus":          VarTimeStraus,
+	"VarTimePippenger":       VarTimePippenger,
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 64, 300} {
+		scalars, points := randInput(n)
+		expected := naive(scalars, points)
+
+		for name, f := range methods {
+			res, err := f(scalars, points)
+			require.Nil(t, err)
+			assert.True(t, expected.Equals(&res), "%s with %d points", name, n)
+		}
+	}
+}
+
+func TestMultiScalarMultEdgeScalars(t *testing.T) {
+	var zero, one, minusOne, big1 ristretto.Scalar
+	zero.SetZero()
+	one.SetOne()
+	minusOne.Neg(&one)
+	big1.SetBigInt(new(big.Int).Lsh(big.NewInt(1), 252))
+
+	scalars := []ristretto.Scalar{zero, one, minusOne, big1, zero}
+	_, points := randInput(len(scalars))
+	points[4].SetZero()
+
+	expected := naive(scalars, points)
+	for name, f := range methods {
+		res, err := f(scalars, points)
+		require.Nil(t, err)
+		assert.True(t, expected.Equals(&res), name)
+	}
+
+	// all scalars zero gives the identity
+	zeros := make([]ristretto.Scalar, 3)
+	for i := range zeros {
+		zeros[i].SetZero()
+	}
+	var identity ristretto.Point
+	identity.SetZero()
+	for name, f := range methods {
+		res, err := f(zeros, points[:3])
+		require.Nil(t, err)
+		assert.True(t, identity.Equals(&res), name)
+	}
+}
+
+func TestMultiScalarMultLengthMismatch(t *testing.T) {
+	scalars, points := randInput(3)
+	for name, f := range methods {
+		_, err := f(scalars[:2], points)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestSignedDigits(t *testing.T) {
+	scalars, _ := randInput(20)
+	var minusOne ristretto.Scalar
+	minusOne.SetOne()
+	minusOne.Neg(&minusOne)
+	scalars = append(scalars, minusOne)
+
+	for _, w := range []uint{4, 6, 7, 8} {
+		for i := range scalars {
+			digits := make([]int32, numDigits(w))
+			signedDigits(&scalars[i], w, digits)
+
+			half := int32(1) << (w - 1)
+			sum := new(big.Int)
+			for j := len(digits) - 1; j >= 0; j-- {
+				if j < len(digits)-1 {
+					assert.True(t, digits[j] >= -half && digits[j] <= half)
+				}
+				sum.Lsh(sum, w)
+				sum.Add(sum, big.NewInt(int64(digits[j])))
+			}
+			assert.Equal(t, scalars[i].BigInt(), sum)
+		}
+	}
+}
+
+func naive(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	var res ristretto.Point
+	res.SetZero()
+	for i := range points {
+		var p ristretto.Point
+		p.ScalarMult(&points[i], &scalars[i])
+		res.Add(&res, &p)
+	}
+	return res
+}
+
+func randInput(n int) ([]ristretto.Scalar, []ristretto.Point) {
+	scalars := make([]ristretto.Scalar, n)
+	points := make([]ristretto.Point, n)
+	for i := 0; i < n; i++ {
+		scalars[i].Rand()
+		points[i].Rand()
+	}
+	return scalars, points
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	benchmarks := map[string]msmFunc{
+		"Naive": func(s []ristretto.Scalar, p []ristretto.Point) (ristretto.Point, error) {
+			return naive(s, p), nil
+		},
+		"Straus":           Straus,
+		"VarTimeStraus":    VarTimeStraus,
+		"VarTimePippenger": VarTimePippenger,
+	}
+
+	for _, n := range []int{16, 64, 256, 1024} {
+		scalars, points := randInput(n)
+		for name, f := range benchmarks {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					f(scalars, points)
+				}
+			})
+		}
+	}
+}
+
+func TestStrausChunks(t *testing.T) {
+	// sizes around the chunk boundary
+	for _, n := range []int{strausChunk - 1, strausChunk, strausChunk + 1, 2*strausChunk + 3} {
+		scalars, points := randInput(n)
+		expected := naive(scalars, points)
+
+		res, err := Straus(scalars, points)
+		require.Nil(t, err)
+		assert.True(t, expected.Equals(&res), "Straus with %d points", n)
+
+		res, err = VarTimeStraus(scalars, points)
+		require.Nil(t, err)
+		assert.True(t, expected.Equals(&res), "VarTimeStraus with %d points", n)
+	}
+}
+
+func TestVarTimeDoubleScalarMult(t *testing.T) {
+	scalars, points := randInput(2)
+	expected := naive(scalars, points)
+
+	res := VarTimeDoubleScalarMult(&scalars[0], &points[0], &scalars[1], &points[1])
+	assert.True(t, expected.Equals(&res))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		VarTimeDoubleScalarMult(&scalars[0], &points[0], &scalars[1], &points[1])
+	})
+	assert.Equal(t, float64(0), allocs)
+}
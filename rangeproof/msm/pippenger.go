@@ -0,0 +1,68 @@
+package msm
+
+import (
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// pippengerWindow returns the digit width for n points
+func pippengerWindow(n int) uint {
+	switch {
+	case n < 500:
+		return 6
+	case n < 800:
+		return 7
+	default:
+		return 8
+	}
+}
+
+// varTimePippenger sorts the points into buckets by digit, so that every
+// window costs one addition per point plus two per bucket
+func varTimePippenger(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	w := pippengerWindow(len(points))
+	n := numDigits(w)
+
+	// digit j of point i is digits[i*n+j]
+	digits := make([]int32, len(points)*n)
+	for i := range points {
+		signedDigits(&scalars[i], w, digits[i*n:(i+1)*n])
+	}
+
+	// digits are in [-2^(w-1), 2^(w-1)], negative ones subtract the point
+	buckets := make([]edwards25519.ExtendedPoint, 1<<(w-1))
+
+	var res ristretto.Point
+	res.SetZero()
+	r := ext(&res)
+
+	var running, sum edwards25519.ExtendedPoint
+	for j := n - 1; j >= 0; j-- {
+		for k := uint(0); k < w; k++ {
+			r.Double(r)
+		}
+
+		for b := range buckets {
+			buckets[b].SetZero()
+		}
+		for i := range points {
+			d := digits[i*n+j]
+			switch {
+			case d > 0:
+				buckets[d-1].Add(&buckets[d-1], ext(&points[i]))
+			case d < 0:
+				buckets[-d-1].Sub(&buckets[-d-1], ext(&points[i]))
+			}
+		}
+
+		// sum(b * bucket_b) as a running sum from the highest bucket
+		running.SetZero()
+		sum.SetZero()
+		for b := len(buckets) - 1; b >= 0; b-- {
+			running.Add(&running, &buckets[b])
+			sum.Add(&sum, &running)
+		}
+		r.Add(r, &sum)
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package msm
+
+import (
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// strausWindow is the width of the signed digits used by Straus
+const strausWindow = 4
+
+// strausDigits is the number of radix 16 digits of a scalar
+const strausDigits = (253+strausWindow-1)/strausWindow + 1
+
+// strausChunk bounds the number of lookup tables held at once. Every
+// chunk costs an extra 253 doublings, which is small next to the
+// additions of its points, and keeps the memory of large sizes bounded
+const strausChunk = 256
+
+// lookupTable holds P, 2P, ..., 8P
+type lookupTable [8]edwards25519.ExtendedPoint
+
+func (t *lookupTable) compute(p *ristretto.Point) {
+	t[0].Set(ext(p))
+	for i := 1; i < len(t); i++ {
+		t[i].Add(&t[i-1], ext(p))
+	}
+}
+
+// selectPoint sets p to d * P in constant time, for d in [-8, 8]
+func (t *lookupTable) selectPoint(p *edwards25519.ExtendedPoint, d int32) {
+	negative := (d >> 31) & 1
+	abs := d - ((-negative & d) << 1)
+
+	p.SetZero()
+	for i := int32(0); i < int32(len(t)); i++ {
+		p.ConditionalSet(&t[i], equal(abs, i+1))
+	}
+
+	var neg edwards25519.ExtendedPoint
+	neg.Neg(p)
+	p.ConditionalSet(&neg, negative)
+}
+
+// add adds d * P to p in variable time
+func (t *lookupTable) add(p *edwards25519.ExtendedPoint, d int32) {
+	switch {
+	case d > 0:
+		p.Add(p, &t[d-1])
+	case d < 0:
+		p.Sub(p, &t[-d-1])
+	}
+}
+
+// equal returns 1 if a == b and 0 otherwise, for small non-negative a and b
+func equal(a, b int32) int32 {
+	x := uint32(a ^ b)
+	x--
+	return int32(x >> 31)
+}
+
+// straus interleaves the windows of every scalar, so that all points
+// share the same 4 doublings per digit
+func straus(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	return strausChunks(scalars, points, strausConstantTime)
+}
+
+func varTimeStraus(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	return strausChunks(scalars, points, strausVarTime)
+}
+
+type strausFunc func(res *edwards25519.ExtendedPoint, tables []lookupTable, digits [][strausDigits]int32)
+
+// strausChunks sums the results of f over chunks of the points,
+// reusing the same tables for every chunk
+func strausChunks(scalars []ristretto.Scalar, points []ristretto.Point, f strausFunc) ristretto.Point {
+	size := len(points)
+	if size > strausChunk {
+		size = strausChunk
+	}
+	tables := make([]lookupTable, size)
+	digits := make([][strausDigits]int32, size)
+
+	var res ristretto.Point
+	res.SetZero()
+
+	var chunk edwards25519.ExtendedPoint
+	for start := 0; start < len(points); start += size {
+		end := start + size
+		if end > len(points) {
+			end = len(points)
+		}
+
+		n := end - start
+		for i := 0; i < n; i++ {
+			tables[i].compute(&points[start+i])
+			signedDigits(&scalars[start+i], strausWindow, digits[i][:])
+		}
+
+		f(&chunk, tables[:n], digits[:n])
+		ext(&res).Add(ext(&res), &chunk)
+	}
+	return res
+}
+
+func strausConstantTime(res *edwards25519.ExtendedPoint, tables []lookupTable, digits [][strausDigits]int32) {
+	res.SetZero()
+
+	var tmp edwards25519.ExtendedPoint
+	for j := strausDigits - 1; j >= 0; j-- {
+		for k := 0; k < strausWindow; k++ {
+			res.Double(res)
+		}
+		for i := range tables {
+			tables[i].selectPoint(&tmp, digits[i][j])
+			res.Add(res, &tmp)
+		}
+	}
+}
+
+func strausVarTime(res *edwards25519.ExtendedPoint, tables []lookupTable, digits [][strausDigits]int32) {
+	res.SetZero()
+
+	started := false
+	for j := strausDigits - 1; j >= 0; j-- {
+		if started {
+			for k := 0; k < strausWindow; k++ {
+				res.Double(res)
+			}
+		}
+		for i := range tables {
+			if digits[i][j] != 0 {
+				tables[i].add(res, digits[i][j])
+				started = true
+			}
+		}
+	}
+}
+
+// VarTimeDoubleScalarMult returns x * P + y * Q in variable time. It
+// does not allocate, which makes it suited to being called once per
+// element of a vector
+func VarTimeDoubleScalarMult(x *ristretto.Scalar, P *ristretto.Point, y *ristretto.Scalar, Q *ristretto.Point) ristretto.Point {
+	var tables [2]lookupTable
+	var digits [2][strausDigits]int32
+
+	tables[0].compute(P)
+	tables[1].compute(Q)
+	signedDigits(x, strausWindow, digits[0][:])
+	signedDigits(y, strausWindow, digits[1][:])
+
+	var res ristretto.Point
+	strausVarTime(ext(&res), tables[:], digits[:])
+	return res
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"sync"
 
 	ristretto "github.com/bwesterb/go-ristretto"
 	generator "github.com/vosbor/dusk-crypto/rangeproof/generators"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
 )
 
 // Pedersen represents a pedersen struct which holds
@@ -18,25 +20,79 @@ type Pedersen struct {
 	BasePoint  ristretto.Point
 }
 
+// tables holds the fixed base tables shared by every Pedersen,
+// computed on first use
+var tables struct {
+	once        sync.Once
+	basePoint   ristretto.Point
+	blindPoint  ristretto.Point
+	base, blind ristretto.ScalarMultTable
+}
+
+func computeTables() {
+	tables.once.Do(func() {
+		tables.basePoint.Derive([]byte("blindPoint"))
+		tables.blindPoint.SetBase()
+		tables.base.Compute(&tables.basePoint)
+		tables.blind.Compute(&tables.blindPoint)
+	})
+}
+
 // New will setup the BaseVector
 // returning a Pedersen struct
 // genData is the byte slice, that will be used
-// to form the unique set of generators
+// to form the unique set of generators.
+// The fixed base tables are shared by the whole process. The vector
+// generators are cached for the lifetime of the process only if genData
+// was registered with generator.Register, otherwise every Pedersen
+// derives its own
 func New(genData []byte) *Pedersen {
 	gen := generator.New(genData)
 
-	var blindPoint ristretto.Point
-	var basePoint ristretto.Point
-
-	basePoint.Derive([]byte("blindPoint"))
-	blindPoint.SetBase()
+	computeTables()
 
 	return &Pedersen{
 		BaseVector: gen,
 		GenData:    genData,
-		BlindPoint: blindPoint,
-		BasePoint:  basePoint,
+		BlindPoint: tables.blindPoint,
+		BasePoint:  tables.basePoint,
+	}
+}
+
+// NewIndexed is like New, but the vector generators are derived from
+// their index, see generator.NewIndexed
+func NewIndexed(genData []byte) *Pedersen {
+	p := New(genData)
+	p.BaseVector = generator.NewIndexed(genData)
+	return p
+}
+
+// BaseMult returns s * BasePoint in constant time, using the fixed
+// base table unless BasePoint was replaced
+func (p *Pedersen) BaseMult(s *ristretto.Scalar) ristretto.Point {
+	computeTables()
+
+	var res ristretto.Point
+	if !p.BasePoint.Equals(&tables.basePoint) {
+		res.ScalarMult(&p.BasePoint, s)
+		return res
+	}
+	res.ScalarMultTable(&tables.base, s)
+	return res
+}
+
+// BlindMult returns s * BlindPoint in constant time, using the fixed
+// base table unless BlindPoint was replaced
+func (p *Pedersen) BlindMult(s *ristretto.Scalar) ristretto.Point {
+	computeTables()
+
+	var res ristretto.Point
+	if !p.BlindPoint.Equals(&tables.blindPoint) {
+		res.ScalarMult(&p.BlindPoint, s)
+		return res
 	}
+	res.ScalarMultTable(&tables.blind, s)
+	return res
 }
 
 // Commitment represents a Pedersen Commitment
@@ -149,8 +205,7 @@ func (p *Pedersen) commitToScalars(blind *ristretto.Scalar, scalars ...ristretto
 
 	if blind != nil {
 
-		var blindPoint ristretto.Point
-		blindPoint.ScalarMult(&p.BlindPoint, blind)
+		blindPoint := p.BlindMult(blind)
 		sum.Add(&sum, &blindPoint)
 	}
 
@@ -162,18 +217,9 @@ func (p *Pedersen) commitToScalars(blind *ristretto.Scalar, scalars ...ristretto
 		// num of scalars to commit should be equal or less than the number of precomputed generators
 	}
 
-	for i := 0; i < n; i++ {
-
-		bi := scalars[i]
-
-		Hi := p.BaseVector.Bases[i]
-
-		// H_i * b_i
-		product := ristretto.Point{}
-		product.ScalarMult(&Hi, &bi)
-
-		sum.Add(&sum, &product)
-	}
+	// <b, H>, the scalars may be secret so it runs in constant time
+	product, _ := msm.MultiScalarMult(scalars, p.BaseVector.Bases[:n])
+	sum.Add(&sum, &product)
 
 	return sum
 }
@@ -185,12 +231,17 @@ func (p *Pedersen) CommitToScalar(v ristretto.Scalar) Commitment {
 	blind := ristretto.Scalar{}
 	blind.Rand()
 
+	return p.CommitWithBlinder(v, blind)
+}
+
+// CommitWithBlinder is like CommitToScalar, but uses the given blinding
+// factor, so that the same commitment can be recreated from v and blind
+func (p *Pedersen) CommitWithBlinder(v, blind ristretto.Scalar) Commitment {
+
 	// v * Base
-	var vBase ristretto.Point
-	vBase.ScalarMult(&p.BasePoint, &v)
+	vBase := p.BaseMult(&v)
 	// blind * BlindPoint
-	var blindPoint ristretto.Point
-	blindPoint.ScalarMult(&p.BlindPoint, &blind)
+	blindPoint := p.BlindMult(&blind)
 
 	var sum ristretto.Point
 	sum.SetZero()
@@ -202,13 +253,31 @@ func (p *Pedersen) CommitToScalar(v ristretto.Scalar) Commitment {
 	}
 }
 
+// blinderDomain separates derived blinders from other hashes to scalars
+var blinderDomain = []byte("vosbor.Pedersen.Blinder")
+
+// DeriveBlinder derives the blinding factor with the given index from a
+// secret key, so that a wallet only needs to store the key to recreate
+// its commitments. Distinct indices give independent blinders
+func DeriveBlinder(key []byte, index uint64) ristretto.Scalar {
+	buf := make([]byte, 0, len(blinderDomain)+len(key)+8)
+	buf = append(buf, blinderDomain...)
+	buf = append(buf, key...)
+
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+	buf = append(buf, idx[:]...)
+
+	var blind ristretto.Scalar
+	blind.Derive(buf)
+	return blind
+}
+
 func (p *Pedersen) VerifyCommitment(v ristretto.Scalar, c Commitment) bool {
 	// v * Base
-	var vBase ristretto.Point
-	vBase.ScalarMult(&p.BasePoint, &v)
+	vBase := p.BaseMult(&v)
 	// blind * BlindPoint
-	var blindPoint ristretto.Point
-	blindPoint.ScalarMult(&p.BlindPoint, &c.BlindingFactor)
+	blindPoint := p.BlindMult(&c.BlindingFactor)
 
 	var sum ristretto.Point
 	sum.SetZero()
@@ -239,8 +308,11 @@ func (p *Pedersen) CommitToVectors(vectors ...[]ristretto.Scalar) Commitment {
 			sum.Add(&sum, &commit)
 		} else {
 
-			genData := append(p.GenData, uint8(i))
+			genData := append(append([]byte{}, p.GenData...), uint8(i))
 			ped2 := New(genData)
+			if p.BaseVector.Indexed() {
+				ped2 = NewIndexed(genData)
+			}
 
 			commit := ped2.commitToScalars(nil, vector...)
 			sum.Add(&sum, &commit)
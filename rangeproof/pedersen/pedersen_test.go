@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	generator "github.com/vosbor/dusk-crypto/rangeproof/generators"
 	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,6 +46,40 @@ func TestPedersenScalar(t *testing.T) {
 	assert.Equal(t, ped.VerifyCommitment(s, commitment), true)
 }
 
+func TestCommitWithBlinder(t *testing.T) {
+	ped := pedersen.New([]byte("random data"))
+
+	var v, blind ristretto.Scalar
+	v.Rand()
+	blind.Rand()
+
+	c := ped.CommitWithBlinder(v, blind)
+	assert.True(t, ped.VerifyCommitment(v, c))
+	assert.True(t, blind.Equals(&c.BlindingFactor))
+
+	// the same opening gives the same commitment
+	assert.True(t, c.Equals(ped.CommitWithBlinder(v, blind)))
+
+	other := blind
+	other.Add(&other, &blind)
+	assert.False(t, c.EqualValue(ped.CommitWithBlinder(v, other)))
+}
+
+func TestDeriveBlinder(t *testing.T) {
+	key := []byte("wallet key")
+
+	b0 := pedersen.DeriveBlinder(key, 0)
+	again := pedersen.DeriveBlinder(key, 0)
+	assert.True(t, b0.Equals(&again))
+
+	b1 := pedersen.DeriveBlinder(key, 1)
+	assert.False(t, b0.Equals(&b1))
+
+	other := pedersen.DeriveBlinder([]byte("other key"), 0)
+	assert.False(t, b0.Equals(&other))
+	assert.Equal(t, int32(1), b0.IsNonZeroI())
+}
+
 func TestEncodeDecode(t *testing.T) {
 	s := ristretto.Scalar{}
 	s.Rand()
@@ -115,3 +150,63 @@ func TestPedersenVector(t *testing.T) {
 
 	assert.Equal(t, expected.Bytes(), []byte(comm.Commit.Bytes()))
 }
+
+func TestFixedBaseTables(t *testing.T) {
+	ped := pedersen.New([]byte("random data"))
+
+	var s ristretto.Scalar
+	s.Rand()
+
+	var expected ristretto.Point
+	expected.ScalarMult(&ped.BasePoint, &s)
+	res := ped.BaseMult(&s)
+	assert.True(t, expected.Equals(&res))
+
+	expected.ScalarMult(&ped.BlindPoint, &s)
+	res = ped.BlindMult(&s)
+	assert.True(t, expected.Equals(&res))
+
+	// replaced bases do not use the tables
+	ped.BasePoint.Rand()
+	expected.ScalarMult(&ped.BasePoint, &s)
+	res = ped.BaseMult(&s)
+	assert.True(t, expected.Equals(&res))
+}
+
+func TestSharedGenerators(t *testing.T) {
+	a := pedersen.New([]byte("shared data"))
+	b := pedersen.New([]byte("shared data"))
+
+	a.BaseVector.Compute(64)
+	b.BaseVector.Compute(32)
+	assert.Equal(t, a.BaseVector.Bases[:32], b.BaseVector.Bases)
+	assert.Equal(t, a.BasePoint, b.BasePoint)
+	assert.Equal(t, a.BlindPoint, b.BlindPoint)
+}
+
+func TestCommitToVectorsIndexed(t *testing.T) {
+	ped := pedersen.NewIndexed([]byte("indexed data"))
+	assert.True(t, ped.BaseVector.Indexed())
+
+	a := make([]ristretto.Scalar, 4)
+	b := make([]ristretto.Scalar, 4)
+	for i := range a {
+		a[i].Rand()
+		b[i].Rand()
+	}
+	c := ped.CommitToVectors(a, b)
+
+	// blind * BlindPoint + <a, G> + <b, H>
+	G := generator.CachedIndexed([]byte("indexed data"), 4)
+	H := generator.CachedIndexed(append([]byte("indexed data"), 1), 4)
+
+	expected := ped.BlindMult(&c.BlindingFactor)
+	for i := range a {
+		var ga, hb ristretto.Point
+		ga.ScalarMult(&G[i], &a[i])
+		hb.ScalarMult(&H[i], &b[i])
+		expected.Add(&expected, &ga)
+		expected.Add(&expected, &hb)
+	}
+	assert.True(t, expected.Equals(&c.Commit))
+}
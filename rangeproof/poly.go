@@ -15,62 +15,63 @@ type polynomial struct {
 	t0, t1, t2     ristretto.Scalar
 }
 
-func computePoly(aL, aR, sL, sR []ristretto.Scalar, y, z ristretto.Scalar) (*polynomial, error) {
+// computePoly builds l(X) and r(X) in a single pass, deriving the powers
+// of y and the terms z^(1+j) * 2^i as it goes, so that only the vectors
+// of the polynomial itself are allocated
+func computePoly(aL, aR, sL, sR []ristretto.Scalar, y, z ristretto.Scalar, n, m uint32) (*polynomial, error) {
+
+	size := n * m
+	if uint32(len(aL)) != size || uint32(len(aR)) != size || uint32(len(sL)) != size || uint32(len(sR)) != size {
+		return nil, errors.New("[ComputePoly] - vectors do not match the aggregation size")
+	}
 
-	// calculate l_0
-	l0 := vector.SubScalar(aL, z)
+	// l_0 = aL - z, l_1 = sL
+	// r_0 = y^nm Had (aR + z) + zMTwoN, r_1 = y^nm Had sR
+	l0 := make([]ristretto.Scalar, size)
+	r0 := make([]ristretto.Scalar, size)
+	r1 := make([]ristretto.Scalar, size)
 
-	// calculate l_1
-	l1 := sL
+	var t0, t1, t2 ristretto.Scalar
+	t0.SetZero()
+	t1.SetZero()
+	t2.SetZero()
 
-	// calculate r_0
-	yNM := vector.ScalarPowers(y, uint32(N*M))
+	var two ristretto.Scalar
+	two.SetBigInt(big.NewInt(2))
 
-	zMTwoN := sumZMTwoN(z)
+	// yi = y^i, zj = z^(j+2), zj2 = z^(j+2) * 2^k for i = j * n + k
+	var yi, zj, zj2 ristretto.Scalar
+	yi.SetOne()
+	zj.Square(&z)
 
-	r0 := vector.AddScalar(aR, z)
+	for j := uint32(0); j < m; j++ {
+		zj2.Set(&zj)
+		for k := uint32(0); k < n; k++ {
+			i := j*n + k
 
-	r0, err := vector.Hadamard(r0, yNM)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - r0 (1)")
-	}
-	r0, err = vector.Add(r0, zMTwoN)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - r0 (2)")
-	}
+			l0[i].Sub(&aL[i], &z)
 
-	// calculate r_1
-	r1, err := vector.Hadamard(yNM, sR)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - r1")
-	}
+			r0[i].Add(&aR[i], &z)
+			r0[i].MulAdd(&r0[i], &yi, &zj2)
+			r1[i].Mul(&yi, &sR[i])
 
-	// calculate t0 // t_0 = <l_0, r_0>
-	t0, err := vector.InnerProduct(l0, r0)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - t0")
-	}
+			// t_0 = <l_0, r_0>
+			// t_1 = <l_0, r_1> + <l_1, r_0>
+			// t_2 = <l_1, r_1>
+			t0.MulAdd(&l0[i], &r0[i], &t0)
+			t1.MulAdd(&l0[i], &r1[i], &t1)
+			t1.MulAdd(&sL[i], &r0[i], &t1)
+			t2.MulAdd(&sL[i], &r1[i], &t2)
 
-	// calculate t1 // t_1 = <l_0, r_1> + <l_1, r_0>
-	t1Left, err := vector.InnerProduct(l1, r0)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - t1Left")
-	}
-	t1Right, err := vector.InnerProduct(l0, r1)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - t1Right")
+			yi.Mul(&yi, &y)
+			zj2.Mul(&zj2, &two)
+		}
+		zj.Mul(&zj, &z)
 	}
-	var t1 ristretto.Scalar
-	t1.Add(&t1Left, &t1Right)
 
-	// calculate t2 // t_2 = <l_1, r_1>
-	t2, err := vector.InnerProduct(l1, r1)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputePoly] - t2")
-	}
 	return &polynomial{
 		l0: l0,
-		l1: l1[:],
+		l1: sL,
 		r0: r0,
 		r1: r1,
 		t0: t0,
@@ -101,29 +102,25 @@ func (p *polynomial) eval(x ristretto.Scalar) ristretto.Scalar {
 
 // l = l_0 + l_1 * x
 func (p *polynomial) computeL(x ristretto.Scalar) ([]ristretto.Scalar, error) {
-
-	lLeft := p.l0
-
-	lRight := vector.MulScalar(p.l1, x)
-
-	l, err := vector.Add(lLeft, lRight)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ComputeL]")
-	}
-	return l, nil
+	return evalVector(p.l0, p.l1, x)
 }
 
 // r = r_0 + r_1 * x
 func (p *polynomial) computeR(x ristretto.Scalar) ([]ristretto.Scalar, error) {
-	rLeft := p.r0
+	return evalVector(p.r0, p.r1, x)
+}
 
-	rRight := vector.MulScalar(p.r1, x)
+// evalVector returns a + b * x
+func evalVector(a, b []ristretto.Scalar, x ristretto.Scalar) ([]ristretto.Scalar, error) {
+	if len(a) != len(b) {
+		return nil, errors.New("length of a does not equal b")
+	}
 
-	r, err := vector.Add(rLeft, rRight)
-	if err != nil {
-		return nil, errors.Wrap(err, "[computeR]")
+	res := make([]ristretto.Scalar, len(a))
+	for i := range res {
+		res[i].MulAdd(&b[i], &x, &a[i])
 	}
-	return r, nil
+	return res, nil
 }
 
 // t_0 = z^2 * v + D(y,z)
@@ -153,51 +150,25 @@ func (p *polynomial) computeT0(y, z ristretto.Scalar, v []ristretto.Scalar, n, m
 }
 
 // calculates sum( z^(1+j) * ( 0^(j-1)n || 2 ^n || 0^(m-j)n ) ) from j = 1 to j=M (71)
-// implementation taken directly from java implementation.
-// XXX: Look into ways to speed this up, and improve readability
-// XXX: pass n and m as parameters
-func sumZMTwoN(z ristretto.Scalar) []ristretto.Scalar {
+func sumZMTwoN(z ristretto.Scalar, n, m uint32) []ristretto.Scalar {
 
-	res := make([]ristretto.Scalar, N*M)
-
-	zM := vector.ScalarPowers(z, uint32(M+3))
+	res := make([]ristretto.Scalar, n*m)
 
 	var two ristretto.Scalar
 	two.SetBigInt(big.NewInt(2))
-	twoN := vector.ScalarPowers(two, N)
-
-	for i := 0; i < M*N; i++ {
-		res[i].SetZero()
-		for j := 1; j <= M; j++ {
-			if (i >= (j-1)*N) && (i < j*N) {
-				res[i].MulAdd(&zM[j+1], &twoN[i-(j-1)*N], &res[i])
-			}
-		}
 
+	// the block j holds z^(j+2) * 2^k
+	var zj ristretto.Scalar
+	zj.Square(&z)
+
+	for j := uint32(0); j < m; j++ {
+		res[j*n].Set(&zj)
+		for k := uint32(1); k < n; k++ {
+			res[j*n+k].Mul(&res[j*n+k-1], &two)
+		}
+		zj.Mul(&zj, &z)
 	}
 	return res
-
-	// Below is the old variation of the above code , for clarity on what the above is doing
-
-	// res := make([]ristretto.Scalar, 0, N*M)
-
-	// var zSq ristretto.Scalar
-	// zSq.Square(&z)
-
-	// zM := vector.ScalarPowers(z, uint32(M))
-	// zM = vector.MulScalar(zM, z)
-
-	// var two ristretto.Scalar
-	// two.SetBigInt(big.NewInt(2))
-	// twoN := vector.ScalarPowers(two, N)
-
-	// for i := 0; i < M; i++ {
-	// 	a := vector.MulScalar(twoN, zM[i])
-	// 	res = append(res, a...)
-	// }
-
-	// return res
-
 }
 
 // D(y,z) - This is the data shared by both prover and verifier
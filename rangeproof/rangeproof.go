@@ -5,28 +5,111 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"math/bits"
 
 	"github.com/pkg/errors"
 
 	ristretto "github.com/bwesterb/go-ristretto"
 	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir" // from dusk network
+	generator "github.com/vosbor/dusk-crypto/rangeproof/generators"
 	"github.com/vosbor/dusk-crypto/rangeproof/innerproduct"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
 	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
 	"github.com/vosbor/dusk-crypto/rangeproof/vector"
 )
 
-// N is number of bits in range
+// N is the default number of bits in range
 // So amount will be between 0...2^(N-1)
 const N = 64
 
-// M is the number of outputs for one bulletproof
-var M = 1
+// validBits returns true if n is a supported bit width
+func validBits(n uint32) bool {
+	switch n {
+	case 8, 16, 32, 64:
+		return true
+	}
+	return false
+}
+
+// maxM is the maximum number of values allowed per rangeproof, padding
+// included. 64 bit proofs of maxM values use 2^18 generators, see
+// DecodeLimited to bound the work of verifying untrusted proofs
+const maxM = 1 << 12
+
+// genData is the seed of the chained generators used by the bulletproof
+var genData = []byte("vosbor.BulletProof.v1")
+
+// genDataIndexed is the label of the indexed generators
+var genDataIndexed = []byte("vosbor.BulletProof.v2")
+
+func init() {
+	// the bases of every proof are cached for the lifetime of the process
+	generator.Register(genData)
+	generator.Register(hData(genData))
+	generator.RegisterIndexed(genDataIndexed)
+	generator.RegisterIndexed(hData(genDataIndexed))
+}
+
+// hData returns the seed of the H vector of the generators of data
+func hData(data []byte) []byte {
+	return append(append([]byte{}, data...), uint8(1))
+}
+
+// Versions of the generators of the G and H vectors. Every proof records
+// the version it was created with, so that proofs created with older
+// generators remain verifiable
+const (
+	// ChainedGenerators derive every base from the previous one
+	ChainedGenerators uint8 = iota
+	// IndexedGenerators derive base i from hash(label || i), so that
+	// they can be computed in parallel
+	IndexedGenerators
+)
 
-// M is the maximum number of values allowed per rangeproof
-const maxM = 16
+// DefaultGenerators is the version of the generators of new proofs
+const DefaultGenerators = IndexedGenerators
+
+// validGenerators returns true if v is a known generator version
+func validGenerators(v uint8) bool {
+	return v == ChainedGenerators || v == IndexedGenerators
+}
+
+// generators holds the bases of a proof over n * m bits
+type generators struct {
+	ped  *pedersen.Pedersen // G vector, value and blinding bases
+	ped2 *pedersen.Pedersen // H vector
+}
+
+// newGenerators returns the bases of the given version for a proof over
+// size bits. The bases come from the process wide generator cache and
+// must not be modified
+func newGenerators(version uint8, size uint32) *generators {
+	newPedersen := pedersen.New
+	data := genData
+	if version == IndexedGenerators {
+		newPedersen = pedersen.NewIndexed
+		data = genDataIndexed
+	}
+
+	ped := newPedersen(data)
+	ped.BaseVector.Compute(size)
+
+	ped2 := newPedersen(hData(data))
+	ped2.BaseVector.Compute(size)
+
+	return &generators{ped: ped, ped2: ped2}
+}
 
 // Proof is the constructed BulletProof
 type Proof struct {
+	// N is the number of bits of every value
+	N uint32
+	// M is the number of values aggregated in the proof,
+	// padded to a power of two
+	M uint32
+	// Generators is the version of the generators of the proof
+	Generators uint8
+
 	V        []pedersen.Commitment // Curve points 32 bytes
 	Blinders []ristretto.Scalar
 	A        ristretto.Point // Curve point 32 bytes
@@ -41,21 +124,58 @@ type Proof struct {
 	IPProof *innerproduct.Proof
 }
 
-// Prove will take a set of scalars as a parameter and prove that it is [0, 2^N)
-func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, error) {
+// Opening is a value together with the blinding factor of its commitment
+type Opening struct {
+	Value   ristretto.Scalar
+	Blinder ristretto.Scalar
+}
+
+// Prove will take a set of openings as a parameter and prove that their values are in [0, 2^N).
+// The commitments to the values are recomputed from the openings and stored in V
+func Prove(openings []Opening, debug bool) (Proof, error) {
+	return ProveBits(N, openings, debug)
+}
+
+// ProveBits proves that every value is in [0, 2^n), where n is 8, 16, 32 or 64.
+// Smaller widths give smaller proofs that are faster to prove and verify
+func ProveBits(n uint32, openings []Opening, debug bool) (Proof, error) {
+	return prove(DefaultGenerators, n, openings, debug)
+}
+
+// prove creates a proof with the given version of the generators
+func prove(version uint8, n uint32, openings []Opening, debug bool) (Proof, error) {
+
+	if !validGenerators(version) {
+		return Proof{}, fmt.Errorf("unknown generator version %d", version)
+	}
+
+	if !validBits(n) {
+		return Proof{}, fmt.Errorf("unsupported bit width %d", n)
+	}
+	if version == ChainedGenerators && n != N {
+		return Proof{}, fmt.Errorf("chained generators only support %d bit proofs", N)
+	}
 
-	if len(v) < 1 {
+	if len(openings) < 1 {
 		return Proof{}, errors.New("length of slice v is zero")
 	}
 
-	M = len(v)
-	if M > maxM {
-		return Proof{}, fmt.Errorf("maximum amount of values must be less than %d", maxM)
+	if len(openings) > maxM {
+		return Proof{}, fmt.Errorf("maximum amount of values is %d", maxM)
+	}
+
+	v := make([]ristretto.Scalar, len(openings))
+	for i := range openings {
+		v[i] = openings[i].Value
+		if v[i].BigInt().BitLen() > int(n) {
+			return Proof{}, fmt.Errorf("value %d does not fit in %d bits", i, n)
+		}
 	}
 
 	// Pad zero values until we have power of two
-	padAmount := innerproduct.DiffNextPow2(uint32(M))
-	M = M + int(padAmount)
+	M := uint32(len(v))
+	padAmount := innerproduct.DiffNextPow2(M)
+	M = M + padAmount
 	for i := uint32(0); i < padAmount; i++ {
 		var zeroScalar ristretto.Scalar
 		zeroScalar.SetZero()
@@ -64,24 +184,24 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 
 	// commitment to values v
 	Vs := make([]pedersen.Commitment, 0, M)
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((N * M)))
+	gens := newGenerators(version, n*M)
+	ped := gens.ped
 
 	// Hash for Fiat-Shamir
-	hs := fiatshamir.HashCacher{Cache: []byte{}}
+	hs := newTranscript(version, n, M)
 
-	for _, commit := range c {
+	for _, o := range openings {
+		commit := ped.CommitWithBlinder(o.Value, o.Blinder)
 		Vs = append(Vs, commit)
 		hs.Append(commit.Commit.Bytes())
 	}
 
-	aLs := make([]ristretto.Scalar, 0, N*M)
-	aRs := make([]ristretto.Scalar, 0, N*M)
+	aLs := make([]ristretto.Scalar, 0, n*M)
+	aRs := make([]ristretto.Scalar, 0, n*M)
 
 	for i := range v {
 		// Compute Bitcommits aL and aR to v
-		BC := BitCommit(v[i].BigInt())
+		BC := bitCommit(v[i].BigInt(), n)
 		aLs = append(aLs, BC.AL...)
 		aRs = append(aRs, BC.AR...)
 	}
@@ -90,7 +210,7 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 	A := computeA(ped, aLs, aRs)
 
 	// // Compute S
-	S, sL, sR := computeS(ped)
+	S, sL, sR := computeS(ped, n*M)
 
 	// // update Fiat-Shamir
 	hs.Append(A.Commit.Bytes(), S.Commit.Bytes())
@@ -99,7 +219,7 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 	y, z := computeYAndZ(hs)
 
 	// compute polynomial
-	poly, err := computePoly(aLs, aRs, sL, sR, y, z)
+	poly, err := computePoly(aLs, aRs, sL, sR, y, z, n, M)
 	if err != nil {
 		return Proof{}, errors.Wrap(err, "[Prove] - poly")
 	}
@@ -134,13 +254,13 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 
 	// START DEBUG
 	if debug {
-		err := debugProve(x, y, z, v, l, r, aLs, aRs, sL, sR)
+		err := debugProve(gens, x, y, z, v, l, r, aLs, aRs, sL, sR, n, M)
 		if err != nil {
 			return Proof{}, errors.Wrap(err, "[Prove] - debugProve")
 		}
 
 		// DEBUG T0
-		testT0, err := debugT0(aLs, aRs, y, z)
+		testT0, err := debugT0(aLs, aRs, y, z, n, M)
 		if err != nil {
 			return Proof{}, errors.Wrap(err, "[Prove] - testT0")
 
@@ -149,7 +269,7 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 			return Proof{}, errors.New("[Prove]: Test t0 value does not match the value calculated from the polynomial")
 		}
 
-		polyt0 := poly.computeT0(y, z, v, N, uint32(M))
+		polyt0 := poly.computeT0(y, z, v, n, M)
 		if !polyt0.Equals(&poly.t0) {
 			return Proof{}, errors.New("[Prove]: t0 value from delta function, does not match the polynomial t0 value(Correct)")
 		}
@@ -169,19 +289,14 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 	hs.Append(x.Bytes(), taux.Bytes(), mu.Bytes(), t.Bytes())
 
 	// calculate inner product proof
-	Q := ristretto.Point{}
 	w := hs.Derive()
-	Q.ScalarMult(&ped.BasePoint, &w)
+	Q := ped.BaseMult(&w)
 
 	var yinv ristretto.Scalar
 	yinv.Inverse(&y)
-	Hpf := vector.ScalarPowers(yinv, uint32(N*M))
-
-	genData = append(genData, uint8(1))
-	ped2 := pedersen.New(genData)
-	ped2.BaseVector.Compute(uint32(N * M))
+	Hpf := vector.ScalarPowers(yinv, n*M)
 
-	H := ped2.BaseVector.Bases
+	H := gens.ped2.BaseVector.Bases
 	G := ped.BaseVector.Bases
 
 	ip, err := innerproduct.Generate(G, H, l, r, Hpf, Q)
@@ -190,15 +305,18 @@ func Prove(v []ristretto.Scalar, c []pedersen.Commitment, debug bool) (Proof, er
 	}
 
 	return Proof{
-		V:       Vs,
-		A:       A.Commit,
-		S:       S.Commit,
-		T1:      T1.Commit,
-		T2:      T2.Commit,
-		t:       t,
-		taux:    taux,
-		mu:      mu,
-		IPProof: ip,
+		N:          n,
+		M:          M,
+		Generators: version,
+		V:          Vs,
+		A:          A.Commit,
+		S:          S.Commit,
+		T1:         T1.Commit,
+		T2:         T2.Commit,
+		t:          t,
+		taux:       taux,
+		mu:         mu,
+		IPProof:    ip,
 	}, nil
 }
 
@@ -211,10 +329,10 @@ func computeA(ped *pedersen.Pedersen, aLs, aRs []ristretto.Scalar) pedersen.Comm
 }
 
 // S = kH + sL*G + sR * H
-func computeS(ped *pedersen.Pedersen) (pedersen.Commitment, []ristretto.Scalar, []ristretto.Scalar) {
+func computeS(ped *pedersen.Pedersen, size uint32) (pedersen.Commitment, []ristretto.Scalar, []ristretto.Scalar) {
 
-	sL, sR := make([]ristretto.Scalar, N*M), make([]ristretto.Scalar, N*M)
-	for i := 0; i < N*M; i++ {
+	sL, sR := make([]ristretto.Scalar, size), make([]ristretto.Scalar, size)
+	for i := uint32(0); i < size; i++ {
 		var randA ristretto.Scalar
 		randA.Rand()
 		sL[i] = randA
@@ -314,45 +432,179 @@ func computeHprime(H []ristretto.Point, y ristretto.Scalar) []ristretto.Point {
 	return Hprimes
 }
 
+// VerifyBits is like Verify, but also requires the proof to be of bit
+// width n. Verify accepts any supported width claimed by the proof, so
+// untrusted proofs should be checked with VerifyBits
+func VerifyBits(n uint32, p Proof) (bool, error) {
+	if p.N != n {
+		return false, fmt.Errorf("expected a proof of %d bits, got %d", n, p.N)
+	}
+	return Verify(p)
+}
+
 // Verify takes a bullet proof and returns true only if the proof was valid
 func Verify(p Proof) (bool, error) {
 
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32(N * M))
-
-	genData = append(genData, uint8(1))
+	if err := p.checkSize(); err != nil {
+		return false, err
+	}
 
-	ped2 := pedersen.New(genData)
-	ped2.BaseVector.Compute(uint32(N * M))
+	gens := newGenerators(p.Generators, p.N*p.M)
+	ped := gens.ped
 
 	G := ped.BaseVector.Bases
-	H := ped2.BaseVector.Bases
+	H := gens.ped2.BaseVector.Bases
+
+	x, y, z, w := p.challenges()
+
+	return megacheckWithC(p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, ped.BasePoint, ped.BlindPoint, p.S, p.T1, p.T2, G, H, p.V, p.N, p.M)
+}
 
-	// Reconstruct the challenges
+// newTranscript starts the Fiat-Shamir hash of a proof. Proofs over the
+// indexed generators start with their shape, so that the challenges
+// depend on the bit width and the aggregation size, and a proof cannot
+// be reinterpreted as another proof of the same N*M. Proofs over the
+// chained generators keep the transcript they had before the shape was
+// bound, as they are always of 64 bits and N*M then fixes M
+func newTranscript(version uint8, N, M uint32) fiatshamir.HashCacher {
 	hs := fiatshamir.HashCacher{Cache: []byte{}}
+	if version == ChainedGenerators {
+		return hs
+	}
+
+	var shape [9]byte
+	shape[0] = version
+	binary.BigEndian.PutUint32(shape[1:5], N)
+	binary.BigEndian.PutUint32(shape[5:], M)
+	hs.Append(shape[:])
+	return hs
+}
+
+// challenges reconstructs the Fiat-Shamir challenges of the proof
+func (p *Proof) challenges() (x, y, z, w ristretto.Scalar) {
+	hs := newTranscript(p.Generators, p.N, p.M)
 	for _, V := range p.V {
 		hs.Append(V.Commit.Bytes())
 	}
 
 	hs.Append(p.A.Bytes(), p.S.Bytes())
-	y, z := computeYAndZ(hs)
+	y, z = computeYAndZ(hs)
 	hs.Append(z.Bytes(), p.T1.Bytes(), p.T2.Bytes())
-	x := computeX(hs)
+	x = computeX(hs)
 	hs.Append(x.Bytes(), p.taux.Bytes(), p.mu.Bytes(), p.t.Bytes())
-	w := hs.Derive()
+	w = hs.Derive()
+	return x, y, z, w
+}
 
-	return megacheckWithC(p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, ped.BasePoint, ped.BlindPoint, p.S, p.T1, p.T2, G, H, p.V)
+// checkSize makes sure the aggregation size of the proof is consistent
+// with its commitments and its inner product proof
+func (p *Proof) checkSize() error {
+
+	if !validBits(p.N) {
+		return fmt.Errorf("unsupported bit width %d", p.N)
+	}
+	if !validGenerators(p.Generators) {
+		return fmt.Errorf("unknown generator version %d", p.Generators)
+	}
+	if p.Generators == ChainedGenerators && p.N != N {
+		return fmt.Errorf("chained generators only support %d bit proofs", N)
+	}
+	if p.M == 0 || p.M > maxM || p.M&(p.M-1) != 0 {
+		return fmt.Errorf("invalid aggregation size %d", p.M)
+	}
+	if len(p.V) == 0 || uint32(len(p.V)) > p.M {
+		return fmt.Errorf("expected between 1 and %d commitments, got %d", p.M, len(p.V))
+	}
+	if p.IPProof == nil {
+		return errors.New("inner product proof is missing")
+	}
+
+	lgNM := bits.TrailingZeros32(p.N * p.M)
+	if len(p.IPProof.L) != lgNM || len(p.IPProof.R) != lgNM {
+		return errors.New("inner product proof does not match the aggregation size")
+	}
+	return nil
 }
 
-func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristretto.Scalar, A, G, H, S, T1, T2 ristretto.Point, GVec, HVec []ristretto.Point, V []pedersen.Commitment) (bool, error) {
+func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristretto.Scalar, A, G, H, S, T1, T2 ristretto.Point, GVec, HVec []ristretto.Point, V []pedersen.Commitment, N, M uint32) (bool, error) {
+
+	if len(GVec) != int(N*M) || len(HVec) != int(N*M) {
+		return false, errors.New("generators do not match the aggregation size")
+	}
+
+	eq, err := megacheckTerms(ipproof, mu, x, y, z, t, taux, w, A, S, T1, T2, V, N, M)
+	if err != nil {
+		return false, err
+	}
+	scalars, points := eq.terms(G, H, GVec, HVec)
+
+	// every term is public, so the variable time engine is safe here
+	sum, err := msm.VarTimeMultiScalarMult(scalars, points)
+	if err != nil {
+		return false, err
+	}
+
+	var zero ristretto.Point
+	zero.SetZero()
 
-	var c1, c2, c3, c4, c5, c6, c7, c8, c9, c10, c11 ristretto.Point
+	ok := zero.Equals(&sum)
+	if !ok {
+		return false, errors.New("megacheck failed")
+	}
+
+	return true, nil
+}
+
+// equation is the combined verification equation of a proof. It holds
+// when the multiscalar multiplication of its terms is the identity.
+// The weights of the generators are kept apart, so that the equations
+// of several proofs can share them
+type equation struct {
+	g, h     []ristretto.Scalar // weights of the G and H vectors
+	gBP, hBP ristretto.Scalar   // weights of the value and blinding bases
+
+	// terms that only belong to the proof
+	scalars []ristretto.Scalar
+	points  []ristretto.Point
+}
+
+// terms returns the scalars and points of the equation
+func (e *equation) terms(G, H ristretto.Point, GVec, HVec []ristretto.Point) ([]ristretto.Scalar, []ristretto.Point) {
+	size := len(e.g) + len(e.h) + len(e.scalars) + 2
+	scalars := make([]ristretto.Scalar, 0, size)
+	points := make([]ristretto.Point, 0, size)
+
+	scalars = append(scalars, e.g...)
+	points = append(points, GVec[:len(e.g)]...)
+	scalars = append(scalars, e.h...)
+	points = append(points, HVec[:len(e.h)]...)
+	scalars = append(scalars, e.gBP, e.hBP)
+	points = append(points, G, H)
+	scalars = append(scalars, e.scalars...)
+	points = append(points, e.points...)
+
+	return scalars, points
+}
+
+// mul multiplies every weight of the equation by r
+func (e *equation) mul(r ristretto.Scalar) {
+	e.g = vector.MulScalar(e.g, r)
+	e.h = vector.MulScalar(e.h, r)
+	e.gBP.Mul(&e.gBP, &r)
+	e.hBP.Mul(&e.hBP, &r)
+	e.scalars = vector.MulScalar(e.scalars, r)
+}
+
+// megacheckTerms returns the combined verification equation of a proof
+func megacheckTerms(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristretto.Scalar, A, S, T1, T2 ristretto.Point, V []pedersen.Commitment, N, M uint32) (*equation, error) {
 
 	var c ristretto.Scalar
 	c.Rand()
 
 	uSq, uInvSq, s := ipproof.VerifScalars()
+	if len(s) != int(N*M) {
+		return nil, errors.New("inner product proof does not match the aggregation size")
+	}
 	sInv := make([]ristretto.Scalar, len(s))
 	copy(sInv, s)
 
@@ -361,42 +613,34 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 		sInv[i], sInv[j] = sInv[j], sInv[i]
 	}
 
+	eq := &equation{}
+
 	// g vector scalars : as + z points : G
 	as := vector.MulScalar(s, ipproof.A)
 	g := vector.AddScalar(as, z)
-	g = vector.MulScalar(g, c)
-
-	c1, err := vector.Exp(g, GVec, len(GVec), 1)
-	if err != nil {
-		return false, err
-	}
+	eq.g = vector.MulScalar(g, c)
 
 	// h vector scalars : y Had (bsInv - zM2N) - z points : H
 	bs := vector.MulScalar(sInv, ipproof.B)
-	zAnd2 := sumZMTwoN(z)
+	zAnd2 := sumZMTwoN(z, N, M)
 	h, err := vector.Sub(bs, zAnd2)
 	if err != nil {
-		return false, errors.Wrap(err, "[h1]")
+		return nil, errors.Wrap(err, "[h1]")
 	}
 
 	var yinv ristretto.Scalar
 	yinv.Inverse(&y)
-	Hpf := vector.ScalarPowers(yinv, uint32(N*M))
+	Hpf := vector.ScalarPowers(yinv, N*M)
 
 	h, err = vector.Hadamard(h, Hpf)
 	if err != nil {
-		return false, errors.Wrap(err, "[h2]")
+		return nil, errors.Wrap(err, "[h2]")
 	}
 	h = vector.SubScalar(h, z)
-	h = vector.MulScalar(h, c)
-
-	c2, err = vector.Exp(h, HVec, len(HVec), 1)
-	if err != nil {
-		return false, err
-	}
+	eq.h = vector.MulScalar(h, c)
 
 	// G basepoint gbp : (c * w(ab-t)) + t-D(y,z) point : G
-	delta := computeDelta(y, z, N, uint32(M))
+	delta := computeDelta(y, z, N, M)
 	var tMinusDelta ristretto.Scalar
 	tMinusDelta.Sub(&t, &delta)
 
@@ -407,89 +651,84 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 	var cw ristretto.Scalar
 	cw.Mul(&c, &w)
 
-	var gBP ristretto.Scalar
-	gBP.MulAdd(&cw, &abMinusT, &tMinusDelta)
-
-	c3.ScalarMult(&G, &gBP)
+	eq.gBP.MulAdd(&cw, &abMinusT, &tMinusDelta)
 
 	// H basepoint hbp : c * mu + taux point: H
-	var cmu ristretto.Scalar
-	cmu.Mul(&mu, &c)
-
-	var hBP ristretto.Scalar
-	hBP.Add(&cmu, &taux)
-
-	c4.ScalarMult(&H, &hBP)
-
-	// scalar :c point: A
-	c5.ScalarMult(&A, &c)
-
-	//  scalar: cx point : S
-	var cx ristretto.Scalar
-	cx.Mul(&c, &x)
-	c6.ScalarMult(&S, &cx)
-
-	// scalar: uSq challenges  points: Lj
-	c7, err = vector.Exp(uSq, ipproof.L, len(ipproof.L), 1)
-	if err != nil {
-		return false, err
-	}
-	c7.PublicScalarMult(&c7, &c)
-
-	// scalar : uInvSq challenges points: Rj
-	c8, err = vector.Exp(uInvSq, ipproof.R, len(ipproof.R), 1)
-	if err != nil {
-		return false, err
-	}
-	c8.PublicScalarMult(&c8, &c)
-
-	// scalar: z_j+2  points: Vj
-	zM := vector.ScalarPowers(z, uint32(M))
-	var zSq ristretto.Scalar
-	zSq.Square(&z)
-	zM = vector.MulScalar(zM, zSq)
-	c9.SetZero()
-	for i := range zM {
-		var temp ristretto.Point
-		temp.PublicScalarMult(&V[i].Commit, &zM[i])
-		c9.Add(&c9, &temp)
-	}
-
-	// scalar : x point: T1
-	c10.PublicScalarMult(&T1, &x)
-
-	// scalar : xSq point: T2
-	var xSq ristretto.Scalar
-	xSq.Square(&x)
-	c11.PublicScalarMult(&T2, &xSq)
-
-	var sum ristretto.Point
-	sum.SetZero()
-	sum.Add(&c1, &c2)
-	sum.Add(&sum, &c3)
-	sum.Add(&sum, &c4)
-	sum.Sub(&sum, &c5)
-	sum.Sub(&sum, &c6)
-	sum.Sub(&sum, &c7)
-	sum.Sub(&sum, &c8)
-	sum.Sub(&sum, &c9)
-	sum.Sub(&sum, &c10)
-	sum.Sub(&sum, &c11)
-
-	var zero ristretto.Point
-	zero.SetZero()
-
-	ok := zero.Equals(&sum)
-	if !ok {
-		return false, errors.New("megacheck failed")
-	}
-
-	return true, nil
+	eq.hBP.MulAdd(&mu, &c, &taux)
+
+	// the remaining terms are subtracted, so their scalars are negated
+
+	// scalar : -c point: A
+	var negC ristretto.Scalar
+	negC.Neg(&c)
+
+	// scalar: -cx point : S
+	var negCx ristretto.Scalar
+	negCx.Mul(&negC, &x)
+
+	// scalar: -c uSq challenges  points: Lj
+	// scalar : -c uInvSq challenges points: Rj
+	l := vector.MulScalar(uSq, negC)
+	r := vector.MulScalar(uInvSq, negC)
+
+	// scalar: -z_j+2  points: Vj
+	// padded values are commitments to zero, which do not contribute
+	var negZSq ristretto.Scalar
+	negZSq.Square(&z)
+	negZSq.Neg(&negZSq)
+	zM := vector.MulScalar(vector.ScalarPowers(z, uint32(len(V))), negZSq)
+
+	// scalar : -x point: T1
+	var negX ristretto.Scalar
+	negX.Neg(&x)
+
+	// scalar : -xSq point: T2
+	var negXSq ristretto.Scalar
+	negXSq.Square(&x)
+	negXSq.Neg(&negXSq)
+
+	size := len(l) + len(r) + len(V) + 4
+	eq.scalars = make([]ristretto.Scalar, 0, size)
+	eq.points = make([]ristretto.Point, 0, size)
+
+	eq.scalars = append(eq.scalars, l...)
+	eq.points = append(eq.points, ipproof.L...)
+	eq.scalars = append(eq.scalars, r...)
+	eq.points = append(eq.points, ipproof.R...)
+	eq.scalars = append(eq.scalars, zM...)
+	for i := range V {
+		eq.points = append(eq.points, V[i].Commit)
+	}
+	eq.scalars = append(eq.scalars, negC, negCx, negX, negXSq)
+	eq.points = append(eq.points, A, S, T1, T2)
+
+	return eq, nil
 }
 
-// Encode a Proof
+// Encode a Proof. Proofs over the indexed generators start with a header
+// of four bytes holding the generator version followed by M as a 24 bit
+// integer, and a byte holding N. Proofs over the chained generators have
+// no header, as they were encoded before generators were versioned, see
+// DecodeChained
 func (p *Proof) Encode(w io.Writer, includeCommits bool) error {
 
+	if p.Generators == ChainedGenerators {
+		if p.N != N {
+			return fmt.Errorf("chained generators only support %d bit proofs", N)
+		}
+	} else {
+		if p.M >= 1<<24 {
+			return fmt.Errorf("invalid aggregation size %d", p.M)
+		}
+		header := uint32(p.Generators)<<24 | p.M
+		if err := binary.Write(w, binary.BigEndian, header); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint8(p.N)); err != nil {
+			return err
+		}
+	}
+
 	if includeCommits {
 		err := pedersen.EncodeCommitments(w, p.V)
 		if err != nil {
@@ -528,19 +767,91 @@ func (p *Proof) Encode(w io.Writer, includeCommits bool) error {
 	return p.IPProof.Encode(w)
 }
 
-// Decode a Proof
+// Decode a Proof. Verifying a proof of N bits over M values derives
+// 2*N*M generators and runs a multiscalar multiplication of about as many
+// points, up to 2^19 for the largest proof Decode accepts. Proofs from
+// untrusted sources should be decoded with DecodeLimited
 func (p *Proof) Decode(r io.Reader, includeCommits bool) error {
+	return p.DecodeLimited(r, includeCommits, maxM*64)
+}
+
+// DecodeLimited is like Decode, but rejects proofs over more than maxSize
+// bits, N*M, before reading their commitments and inner product proof
+func (p *Proof) DecodeLimited(r io.Reader, includeCommits bool, maxSize uint32) error {
+
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var header uint32
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return err
+	}
+	p.Generators = uint8(header >> 24)
+	if p.Generators == ChainedGenerators {
+		return errors.New("proofs over the chained generators have no header, see DecodeChained")
+	}
+	if !validGenerators(p.Generators) {
+		return fmt.Errorf("unknown generator version %d", p.Generators)
+	}
+	p.M = header & (1<<24 - 1)
+	if p.M == 0 || p.M > maxM || p.M&(p.M-1) != 0 {
+		return fmt.Errorf("invalid aggregation size %d", p.M)
+	}
+
+	var n uint8
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	p.N = uint32(n)
+	if !validBits(p.N) {
+		return fmt.Errorf("unsupported bit width %d", p.N)
+	}
+	if p.N*p.M > maxSize {
+		return fmt.Errorf("proof over %d bits exceeds the maximum of %d", p.N*p.M, maxSize)
+	}
+
+	return p.decodeBody(r, includeCommits)
+}
+
+// DecodeChained decodes a proof of 64 bits over the chained generators,
+// which is encoded without a header. m is the number of values of the
+// proof, padded to a power of two, which the encoding does not hold
+func (p *Proof) DecodeChained(r io.Reader, includeCommits bool, m uint32) error {
 
 	if p == nil {
 		return errors.New("struct is nil")
 	}
+	if m == 0 || m > maxM || m&(m-1) != 0 {
+		return fmt.Errorf("invalid aggregation size %d", m)
+	}
+
+	p.Generators = ChainedGenerators
+	p.N = N
+	p.M = m
+	return p.decodeBody(r, includeCommits)
+}
+
+// decodeBody decodes what follows the header of a proof of known shape
+func (p *Proof) decodeBody(r io.Reader, includeCommits bool) error {
 
 	if includeCommits {
-		comms, err := pedersen.DecodeCommitments(r)
-		if err != nil {
+		// read like pedersen.DecodeCommitments, without allocating
+		// more commitments than the proof can hold
+		var lenV uint32
+		if err := binary.Read(r, binary.BigEndian, &lenV); err != nil {
 			return err
 		}
-		p.V = comms
+		if lenV == 0 || lenV > p.M {
+			return fmt.Errorf("expected between 1 and %d commitments, got %d", p.M, lenV)
+		}
+
+		p.V = make([]pedersen.Commitment, lenV)
+		for i := range p.V {
+			if err := p.V[i].Decode(r); err != nil {
+				return err
+			}
+		}
 	}
 
 	err := readerToPoint(r, &p.A)
@@ -571,12 +882,16 @@ func (p *Proof) Decode(r io.Reader, includeCommits bool) error {
 	if err != nil {
 		return err
 	}
+	// the inner product proof has one round per halving of the N*M bits
 	p.IPProof = &innerproduct.Proof{}
-	return p.IPProof.Decode(r)
+	return p.IPProof.DecodeRounds(r, uint32(bits.TrailingZeros32(p.N*p.M)))
 }
 
 // Equals returns proof equality with commitments
 func (p *Proof) Equals(other Proof, includeCommits bool) bool {
+	if p.N != other.N || p.M != other.M || p.Generators != other.Generators {
+		return false
+	}
 	if len(p.V) != len(other.V) && includeCommits {
 		return false
 	}
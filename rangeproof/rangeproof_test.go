@@ -2,10 +2,15 @@ package rangeproof
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"math/big"
+	"math/bits"
 	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 
 	ristretto "github.com/bwesterb/go-ristretto"
@@ -13,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
 	"github.com/vosbor/dusk-crypto/rangeproof/innerproduct"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
 	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
 )
 
@@ -43,6 +49,401 @@ func TestEncodeDecode(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestAggregationSizes(t *testing.T) {
+	for _, m := range []int{1, 3, 5, 16} {
+		p := generateProof(m, t)
+		assert.Equal(t, uint32(1)<<uint(bits.Len(uint(m-1))), p.M)
+
+		ok, err := Verify(*p)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// The aggregation size travels with the proof
+		buf := &bytes.Buffer{}
+		require.Nil(t, p.Encode(buf, true))
+
+		var decodedProof Proof
+		require.Nil(t, decodedProof.Decode(buf, true))
+		assert.Equal(t, p.M, decodedProof.M)
+
+		ok, err = Verify(decodedProof)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestLargeAggregation(t *testing.T) {
+	openings := randomValues(100)
+
+	p, err := Prove(openings, false)
+	require.Nil(t, err)
+	assert.Equal(t, uint32(128), p.M)
+	assert.Equal(t, 100, len(p.V))
+
+	ok, err := Verify(p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+
+	var decodedProof Proof
+	require.Nil(t, decodedProof.Decode(buf, true))
+
+	ok, err = VerifyBatch([]Proof{decodedProof, *generateProof(3, t)})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestProveOpenings(t *testing.T) {
+	openings := randomValues(3)
+
+	p, err := Prove(openings, false)
+	require.Nil(t, err)
+
+	// V holds the commitments to the openings
+	ped := pedersen.New(genData)
+	require.Equal(t, len(openings), len(p.V))
+	for i, o := range openings {
+		c := ped.CommitWithBlinder(o.Value, o.Blinder)
+		assert.True(t, c.Equals(p.V[i]))
+	}
+
+	ok, err := Verify(p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestAggregationLimit(t *testing.T) {
+	_, err := Prove(make([]Opening, maxM+1), false)
+	assert.NotNil(t, err)
+
+	p := generateProof(1, t)
+	p.M = maxM * 2
+	_, err = Verify(*p)
+	assert.NotNil(t, err)
+}
+
+func TestInvalidAggregationSize(t *testing.T) {
+	p := generateProof(2, t)
+
+	for _, m := range []uint32{0, 1, 3, 4, 32} {
+		tampered := *p
+		tampered.M = m
+
+		ok, err := Verify(tampered)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	}
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	encoded := buf.Bytes()
+
+	// M = 3 is not a power of two
+	encoded[3] = 3
+	var decodedProof Proof
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded), true))
+
+	// M = 4 does not match the inner product proof
+	encoded[3] = 4
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded), true))
+}
+
+func TestBitWidths(t *testing.T) {
+	for _, n := range []uint32{8, 16, 32, 64} {
+		var max big.Int
+		max.Lsh(big.NewInt(1), uint(n))
+		max.Sub(&max, big.NewInt(1))
+
+		openings := commitToValues([]*big.Int{big.NewInt(0), &max, big.NewInt(100)})
+
+		p, err := ProveBits(n, openings, false)
+		require.Nil(t, err)
+		assert.Equal(t, n, p.N)
+		assert.Equal(t, bits.TrailingZeros32(n*p.M), len(p.IPProof.L))
+
+		ok, err := Verify(p)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// The bit width travels with the proof
+		buf := &bytes.Buffer{}
+		require.Nil(t, p.Encode(buf, true))
+
+		var decodedProof Proof
+		require.Nil(t, decodedProof.Decode(buf, true))
+		assert.Equal(t, n, decodedProof.N)
+
+		ok, err = Verify(decodedProof)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestGeneratorVersions(t *testing.T) {
+	openings := randomValues(3)
+
+	for _, version := range []uint8{ChainedGenerators, IndexedGenerators} {
+		p, err := prove(version, N, openings, false)
+		require.Nil(t, err)
+		assert.Equal(t, version, p.Generators)
+
+		ok, err := Verify(p)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// the version is the first byte of the encoding, except for
+		// chained proofs which have no header
+		buf := &bytes.Buffer{}
+		require.Nil(t, p.Encode(buf, true))
+
+		var decodedProof Proof
+		if version == ChainedGenerators {
+			assert.Equal(t, []byte{0, 0, 0, 3}, buf.Bytes()[:4])
+			require.Nil(t, decodedProof.DecodeChained(buf, true, 4))
+		} else {
+			assert.Equal(t, []byte{version, 0, 0, 4}, buf.Bytes()[:4])
+			require.Nil(t, decodedProof.Decode(buf, true))
+		}
+		assert.Equal(t, version, decodedProof.Generators)
+		assert.True(t, p.Equals(decodedProof, true))
+
+		ok, err = Verify(decodedProof)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// a proof does not verify against the other generators
+		decodedProof.Generators = 1 - version
+		ok, _ = Verify(decodedProof)
+		assert.False(t, ok)
+	}
+
+	// new proofs use the default generators
+	p, err := Prove(openings, false)
+	require.Nil(t, err)
+	assert.Equal(t, DefaultGenerators, p.Generators)
+
+	// unknown versions are rejected
+	_, err = prove(7, N, openings, false)
+	assert.NotNil(t, err)
+
+	p.Generators = 7
+	_, err = Verify(p)
+	assert.NotNil(t, err)
+
+	p.Generators = DefaultGenerators
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	buf.Bytes()[0] = 7
+	var decodedProof Proof
+	assert.NotNil(t, decodedProof.Decode(buf, true))
+}
+
+func TestBitWidthEnforced(t *testing.T) {
+
+	// 256 does not fit in 8 bits
+	openings := commitToValues([]*big.Int{big.NewInt(256)})
+	_, err := ProveBits(8, openings, false)
+	assert.NotNil(t, err)
+
+	_, err = ProveBits(12, openings, false)
+	assert.NotNil(t, err)
+
+	// A proof cannot claim another width than the one it was made for
+	openings = commitToValues([]*big.Int{big.NewInt(200)})
+	p, err := ProveBits(16, openings, false)
+	require.Nil(t, err)
+
+	for _, n := range []uint32{0, 8, 12, 32, 64} {
+		tampered := p
+		tampered.N = n
+
+		ok, err := Verify(tampered)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	}
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	encoded := buf.Bytes()
+
+	for _, n := range []uint8{12, 32} {
+		encoded[4] = n
+		var decodedProof Proof
+		assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded), true))
+	}
+}
+
+func TestDecodeLimited(t *testing.T) {
+	openings := commitToValues([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)})
+	p, err := ProveBits(32, openings, false)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	encoded := buf.Bytes()
+
+	var decodedProof Proof
+	require.Nil(t, decodedProof.DecodeLimited(bytes.NewReader(encoded), true, 32*4))
+	assert.True(t, p.Equals(decodedProof, true))
+
+	assert.NotNil(t, decodedProof.DecodeLimited(bytes.NewReader(encoded), true, 32*4-1))
+
+	// the largest proof is rejected from its header alone
+	header := []byte{DefaultGenerators, 0, 0x10, 0, 64}
+	err = decodedProof.DecodeLimited(bytes.NewReader(header), false, maxM*64-1)
+	assert.Contains(t, err.Error(), "exceeds the maximum")
+
+	// hostile numbers of commitments are rejected before any allocation
+	tampered := append([]byte{}, encoded...)
+	binary.BigEndian.PutUint32(tampered[5:9], 0xFFFFFFFF)
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(tampered), true))
+	binary.BigEndian.PutUint32(tampered[5:9], 5)
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(tampered), true))
+}
+
+// A proof stops where its encoding ends, so that proofs can be
+// written one after another in a stream
+func TestDecodeStream(t *testing.T) {
+	first := generateProof(2, t)
+	second := generateProof(3, t)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, first.Encode(buf, true))
+	require.Nil(t, second.Encode(buf, true))
+	buf.WriteString("trailing")
+
+	var decodedProof Proof
+	require.Nil(t, decodedProof.Decode(buf, true))
+	assert.True(t, first.Equals(decodedProof, true))
+
+	require.Nil(t, decodedProof.Decode(buf, true))
+	assert.True(t, second.Equals(decodedProof, true))
+
+	assert.Equal(t, "trailing", buf.String())
+}
+
+func TestVerifyBits(t *testing.T) {
+	openings := commitToValues([]*big.Int{big.NewInt(200)})
+	p, err := ProveBits(16, openings, false)
+	require.Nil(t, err)
+
+	ok, err := VerifyBits(16, p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyBits(64, p)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	// the width of a decoded proof is the one claimed by its encoding
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	var decodedProof Proof
+	require.Nil(t, decodedProof.Decode(buf, true))
+
+	ok, err = VerifyBits(8, decodedProof)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+// The challenges depend on the shape of the proof, so that a proof of
+// 64 bits for one value is not a proof of 8 bits for eight values
+func TestChallengesBindShape(t *testing.T) {
+	p := generateProof(1, t)
+	x, y, z, w := p.challenges()
+
+	shapes := []func(p *Proof){
+		func(p *Proof) { p.N = 8; p.M = 8 },
+		func(p *Proof) { p.N = 32 },
+		func(p *Proof) { p.M = 2 },
+		func(p *Proof) { p.Generators = ChainedGenerators },
+	}
+
+	for _, shape := range shapes {
+		tampered := *p
+		shape(&tampered)
+
+		x2, y2, z2, w2 := tampered.challenges()
+		assert.False(t, x.Equals(&x2))
+		assert.False(t, y.Equals(&y2))
+		assert.False(t, z.Equals(&z2))
+		assert.False(t, w.Equals(&w2))
+
+		ok, _ := Verify(tampered)
+		assert.False(t, ok)
+	}
+}
+
+// preSeriesProof is a proof of 42 made and encoded with its commitment
+// before generators were versioned and the bit width was configurable
+const preSeriesProof = "00000001d27fed03129aba46aca4c1af533f2a039766e3540a0470cba71b9c61b48df8551408152911c81465a860cb98afd2288f3ba741abce13f9c47acc5bc8739f4475c4ce65e2491cbab9d6137a4144dc82b7e7b8189426cd7026df40fae816107e708e58ee30c7d2631a0b9b242a012c8267a011b3e9296e65a7fdd44ffb0a643c24c06a749a38e2830234941244c2fdc9cdda9794711aca742331950ead0b0ad007ebfb3c6b1a4b5b63169f3b3e7704d4b808c1b4eb8dc5b57525dd8617cdd2d507b5522edd0b743d36e3a875ab4c75bac7ca81875160763e222a79648ef4e1f701d5d478bea064829fc23170edafa1e6d4c879de44f524e5e21514a0a6ade86201d7030097ac8687475108cf81dfcba11d6a495c6f6408eebc733559b0b4ec62016e3a5b4653dbc50650c7cc18849c4bb719523167e699166963a5c42a4ea0300c520ed8538ff7f784474aecb5df235e477b311be6e07ae82a5752a3e48335e327442559ada889b89d5ebd7ac13e9dfb027be6473400b0ba4940db54ec03b9fc2a8a438e66d0e1965d6a674f9945569df3584ba506988128b3384442e9812ce0558a05239d6e166a1c8f865407e69d3eb8a036adfe516ee9a00a0172e58d2164669c1d8fc358e5a21066c63c37748cfaca493a77bdda18da47e82c9920c2d3e214ae751f8fb36b8f7636180188ad295d21b9242d1ea471bfae77f85aad5c6c8b2a8e8813741bc1a188b1ee871dcd4403ee86058ced6ae56287ba0f1faa170f0f7e9cfc98edb0ca27996cd66b022847ead4e491bc3f822266923298be53220cf05a8686b93a3b8d1ccc86e336c862c4fe68547959dc53a624382429118811dbc25ebe972d7ec87b9d97b80021cc9d48e43801f139c09750284bf7c98bfd721f6d600213fdb039de9d2d4bdf2ee9106eece158c5746c882d8c3e3d8c529e54f04675e4ce4357ea138a23b461846fa1283296b8b11bfa67b0de3c3e58ae35ed37c05d"
+
+func TestDecodeChainedPreSeries(t *testing.T) {
+	encoded, err := hex.DecodeString(preSeriesProof)
+	require.Nil(t, err)
+
+	var p Proof
+	require.Nil(t, p.DecodeChained(bytes.NewReader(encoded), true, 1))
+	assert.Equal(t, ChainedGenerators, p.Generators)
+	assert.Equal(t, uint32(N), p.N)
+
+	ok, err := VerifyBits(N, p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyBatch([]Proof{p})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// the proof encodes back to the same bytes
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	assert.Equal(t, encoded, buf.Bytes())
+
+	// it has no header, so that Decode cannot read it
+	var decodedProof Proof
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded), true))
+
+	// it only verifies with the aggregation size it was made for
+	assert.NotNil(t, decodedProof.DecodeChained(bytes.NewReader(encoded), true, 2))
+
+	// chained generators only come in 64 bits
+	_, err = prove(ChainedGenerators, 32, randomValues(1), false)
+	assert.NotNil(t, err)
+}
+
+// Proofs of different aggregation sizes are proven and verified
+// concurrently, run with -race
+func TestConcurrentProveVerify(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(m int) {
+			defer wg.Done()
+
+			openings := randomValues(m)
+			p, err := Prove(openings, false)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := Verify(p); err != nil {
+				errs <- err
+			}
+		}(i%4 + 1)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+}
+
 func TestComputeMu(t *testing.T) {
 	var one ristretto.Scalar
 	one.SetOne()
@@ -60,84 +461,122 @@ func TestComputeMu(t *testing.T) {
 func generateProof(m int, t *testing.T) *Proof {
 
 	// XXX: m must be a multiple of two due to inner product proof
-	amounts := []ristretto.Scalar{}
-	commitments := make([]pedersen.Commitment, 0, M)
-
-	// N is number of bits in range
-	// So amount will be between 0...2^(N-1)
-	const N = 64
-
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((M * N)))
-
-	for i := 0; i < m; i++ {
-
-		var amount ristretto.Scalar
-		n := rand.Int63()
-		amount.SetBigInt(big.NewInt(n))
-		c := ped.CommitToScalar(amount)
-
-		amounts = append(amounts, amount)
-		commitments = append(commitments, c)
-	}
+	openings := randomValues(m)
 
 	// Prove
-	p, err := Prove(amounts, commitments, true)
+	p, err := Prove(openings, true)
 	require.Nil(t, err)
 	return &p
 }
 
-func BenchmarkProve(b *testing.B) {
-
-	var amount ristretto.Scalar
+func randomValues(m int) []Opening {
+	openings := make([]Opening, m)
+	for i := range openings {
+		openings[i].Value.SetBigInt(big.NewInt(rand.Int63()))
+		openings[i].Blinder.Rand()
+	}
+	return openings
+}
 
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((N)))
-	commitments := make([]pedersen.Commitment, 0, M)
+func commitToValues(values []*big.Int) []Opening {
+	openings := make([]Opening, len(values))
+	for i := range values {
+		openings[i].Value.SetBigInt(values[i])
+		openings[i].Blinder.Rand()
+	}
+	return openings
+}
 
-	amount.SetBigInt(big.NewInt(100000))
-	c := ped.CommitToScalar(amount)
+func BenchmarkProve(b *testing.B) {
 
-	commitments = append(commitments, c)
+	var o Opening
+	o.Value.SetBigInt(big.NewInt(100000))
+	o.Blinder.Rand()
 
 	for i := 0; i < 100; i++ {
 
 		// Prove
-		Prove([]ristretto.Scalar{amount}, commitments, false)
+		Prove([]Opening{o}, false)
 	}
 
 }
 
+// aggregationSizes are the numbers of values of the benchmarks
+var aggregationSizes = []int{1, 2, 4, 8, 16, 32, 64, 128, 256}
+
+func BenchmarkProveAggregated(b *testing.B) {
+
+	for _, m := range aggregationSizes {
+		openings := randomValues(m)
+
+		b.Run(fmt.Sprintf("M=%d", m), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Prove(openings, false)
+			}
+		})
+	}
+}
+
 func BenchmarkVerify(b *testing.B) {
 
-	var amount ristretto.Scalar
+	for _, m := range aggregationSizes {
+		openings := randomValues(m)
+		p, err := Prove(openings, false)
+		require.Nil(b, err)
 
-	genData := []byte("vosbor.BulletProof.v1")
-	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((N)))
-	commitments := make([]pedersen.Commitment, 0, M)
+		b.Run(fmt.Sprintf("M=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Verify(p)
+			}
+		})
+	}
+}
 
-	amount.SetBigInt(big.NewInt(100000))
-	c := ped.CommitToScalar(amount)
-	commitments = append(commitments, c)
+// BenchmarkMegacheck compares summing the terms of the verification
+// equation one product at a time with a single multiscalar multiplication
+func BenchmarkMegacheck(b *testing.B) {
 
-	p, _ := Prove([]ristretto.Scalar{amount}, commitments, false)
+	for _, m := range []int{1, 2, 4, 8, 16} {
+		openings := randomValues(m)
+		p, err := Prove(openings, false)
+		require.Nil(b, err)
 
-	b.ResetTimer()
+		scalars, points := proofTerms(b, p)
 
-	for i := 0; i < 100; i++ {
-		// Verify
-		Verify(p)
+		b.Run(fmt.Sprintf("Naive/M=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var sum ristretto.Point
+				sum.SetZero()
+				for j := range points {
+					var term ristretto.Point
+					term.ScalarMult(&points[j], &scalars[j])
+					sum.Add(&sum, &term)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("MSM/M=%d", m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				msm.VarTimeMultiScalarMult(scalars, points)
+			}
+		})
 	}
+}
+
+// proofTerms returns the terms of the verification equation of p
+func proofTerms(b *testing.B, p Proof) ([]ristretto.Scalar, []ristretto.Point) {
+	gens := newGenerators(p.Generators, p.N*p.M)
 
+	x, y, z, w := p.challenges()
+
+	eq, err := megacheckTerms(p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, p.S, p.T1, p.T2, p.V, p.N, p.M)
+	require.Nil(b, err)
+	return eq.terms(gens.ped.BasePoint, gens.ped.BlindPoint, gens.ped.BaseVector.Bases, gens.ped2.BaseVector.Bases)
 }
 
 func TestProve(t *testing.T) {
 	type args struct {
-		v     []ristretto.Scalar
-		c     []pedersen.Commitment
+		v     []Opening
 		debug bool
 	}
 	tests := []struct {
@@ -150,7 +589,7 @@ func TestProve(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Prove(tt.args.v, tt.args.c, tt.args.debug)
+			got, err := Prove(tt.args.v, tt.args.debug)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Prove() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -186,7 +625,8 @@ func Test_computeA(t *testing.T) {
 
 func Test_computeS(t *testing.T) {
 	type args struct {
-		ped *pedersen.Pedersen
+		ped  *pedersen.Pedersen
+		size uint32
 	}
 	tests := []struct {
 		name  string
@@ -199,7 +639,7 @@ func Test_computeS(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, got2 := computeS(tt.args.ped)
+			got, got1, got2 := computeS(tt.args.ped, tt.args.size)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("computeS() got = %v, want %v", got, tt.want)
 			}
@@ -370,6 +810,8 @@ func Test_megacheckWithC(t *testing.T) {
 		GVec    []ristretto.Point
 		HVec    []ristretto.Point
 		V       []pedersen.Commitment
+		N       uint32
+		M       uint32
 	}
 	tests := []struct {
 		name    string
@@ -381,7 +823,7 @@ func Test_megacheckWithC(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := megacheckWithC(tt.args.ipproof, tt.args.mu, tt.args.x, tt.args.y, tt.args.z, tt.args.t, tt.args.taux, tt.args.w, tt.args.A, tt.args.G, tt.args.H, tt.args.S, tt.args.T1, tt.args.T2, tt.args.GVec, tt.args.HVec, tt.args.V)
+			got, err := megacheckWithC(tt.args.ipproof, tt.args.mu, tt.args.x, tt.args.y, tt.args.z, tt.args.t, tt.args.taux, tt.args.w, tt.args.A, tt.args.G, tt.args.H, tt.args.S, tt.args.T1, tt.args.T2, tt.args.GVec, tt.args.HVec, tt.args.V, tt.args.N, tt.args.M)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("megacheckWithC() error = %v, wantErr %v", err, tt.wantErr)
 				return
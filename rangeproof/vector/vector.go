@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/msm"
 )
 
 // Add adds two scalar slices a and b,
@@ -112,7 +113,8 @@ func InnerProduct(a, b []ristretto.Scalar) (ristretto.Scalar, error) {
 	return res, nil
 }
 
-// Exp exponentiates and sums a vector a to b, creating a commitment
+// Exp exponentiates and sums a vector a to b, creating a commitment.
+// It runs in constant time, as a may hold secrets
 func Exp(a []ristretto.Scalar, b []ristretto.Point, N, M int) (ristretto.Point, error) {
 	result := ristretto.Point{} // defaults to zero
 	result.SetZero()
@@ -125,19 +127,7 @@ func Exp(a []ristretto.Scalar, b []ristretto.Point, N, M int) (ristretto.Point,
 		return result, errors.New("length of scalar a is not less than N*M")
 	}
 
-	for i := range b {
-
-		scalar := a[i]
-		point := b[i]
-
-		var sum ristretto.Point
-		sum.ScalarMult(&point, &scalar)
-
-		result.Add(&result, &sum)
-
-	}
-
-	return result, nil
+	return msm.MultiScalarMult(a, b)
 }
 
 // ScalarPowers constructs a vector of powers
@@ -2,8 +2,6 @@ package blsag
 
 import (
 	"bytes"
-	"math/rand"
-	"time"
 
 	ristretto "github.com/bwesterb/go-ristretto"
 )
@@ -18,19 +16,29 @@ type RingSignature struct {
 
 // Sign will create the MLSAG components that can be used to verify the owner
 // Returns keyimage, a c val,
+// Sign does not validate the ring and cannot return an error, it panics if
+// the system random source fails. New code should use SignChecked
 func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature {
 
+	// secret j index
+	j, err := randomIndex(len(mixin) + 1)
+	if err != nil {
+		panic(err)
+	}
+
+	return sign(m, mixin, sK, j, hashPubKey)
+}
+
+// sign creates the signature with the signers key at index j of the ring.
+// hp maps every ring key to the base of its key image
+func sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar, j int, hp hashToPoint) RingSignature {
+
 	// pubKey pK such that pK = sK * G
 	var pK ristretto.Point
 	pK.ScalarMultBase(&sK)
 
-	// secret j index
-	rand.Seed(time.Now().UnixNano())
-	j := rand.Intn(len(mixin) + 1)
-
 	// Hp(pK)
-	var hPK ristretto.Point
-	hPK.Derive(pK.Bytes())
+	hPK := hp(pK)
 
 	// I = xHp(pK)
 	var I ristretto.Point
@@ -42,7 +50,7 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 
 	// generate s_i where i =/= j and s_i E Zq
 	sVals := make([]ristretto.Scalar, len(mixin)+1)
-	for i := 1; i < len(sVals); i++ {
+	for i := 0; i < len(sVals); i++ {
 		var s ristretto.Scalar
 		s.Rand()
 		sVals[i] = s
@@ -68,6 +76,8 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 	cVals[jPlus1] = cPlus1
 
 	pubKeys := make([]ristretto.Point, len(mixin)+1)
+	copy(pubKeys, mixin[:j])
+	copy(pubKeys[j+1:], mixin[j:])
 	pubKeys[j] = pK // add signer
 
 	for i := j + 1; ; i++ {
@@ -79,7 +89,7 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 
 		k := (i + 1) % (len(pubKeys))
 
-		c, _, _ := computeCLR(m, I, pubKeys[l], sVals[l], cPlus1)
+		c, _, _ := computeCLR(m, I, pubKeys[l], hp(pubKeys[l]), sVals[l], cPlus1)
 
 		cVals[k] = c
 		cPlus1 = c
@@ -100,77 +110,23 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 }
 
 // Verify takes a message and a ringsig
-// returns true if the message was signed by a member of the ring
+// returns true if the message was signed by a member of the ring.
+// It applies the checks of VerifyChecked, so that rings smaller than
+// MinRingSize or holding duplicate or identity keys are rejected.
+// Use VerifyChecked to learn why a signature is rejected
 func Verify(m []byte, ringsig RingSignature) bool {
-
-	// Two conditions are that:
-	// c_n+1 = c1 in 1 i mod n
-	// For all i, c_i+1 = H(m, L_i, R_i)
-
-	numPubKeys := len(ringsig.PubKeys)
-
-	currC := ringsig.C // this is first c value c[0]
-
-	Ls := make([]ristretto.Point, numPubKeys)
-	Rs := make([]ristretto.Point, numPubKeys)
-	Cs := make([]ristretto.Scalar, numPubKeys)
-
-	for i := 0; i < numPubKeys; i++ {
-
-		// First calculate Cs, Ls, Rs
-
-		cPlus1, L, R := computeCLR(m, ringsig.I, ringsig.PubKeys[i], ringsig.S[i], currC)
-
-		k := (i + 1) % numPubKeys
-
-		Cs[k] = cPlus1
-
-		Ls[i] = L
-		Rs[i] = R
-
-		currC = cPlus1
-	}
-
-	// check that c_i+1 = h(m || L_i || R_i)
-
-	if len(Ls) != len(Rs) && len(Rs) != len(Cs) {
-		return false
-	}
-
-	for i := range Cs {
-
-		buf := new(bytes.Buffer)
-		appendToBuf(buf, m, Ls[i].Bytes(), Rs[i].Bytes())
-
-		var cPlus1 ristretto.Scalar
-		cPlus1.Derive(buf.Bytes())
-
-		k := (i + 1) % len(Cs)
-
-		if !Cs[k].Equals(&cPlus1) {
-			return false
-		}
-
-	}
-
-	// c_n+1 = c[0]
-	if Cs[0] != ringsig.C {
-		return false
-	}
-
-	return true
+	ok, err := VerifyChecked(m, ringsig)
+	return ok && err == nil
 }
 
 // returns C, L, R
-func computeCLR(message []byte, I ristretto.Point, pubKey ristretto.Point, s ristretto.Scalar, c ristretto.Scalar) (ristretto.Scalar, ristretto.Point, ristretto.Point) {
-	var L1, L2, L, R1, R2, R, tmpPubKey, HTmpPubKey ristretto.Point
+func computeCLR(message []byte, I ristretto.Point, pubKey ristretto.Point, HTmpPubKey ristretto.Point, s ristretto.Scalar, c ristretto.Scalar) (ristretto.Scalar, ristretto.Point, ristretto.Point) {
+	var L1, L2, L, R1, R2, R, tmpPubKey ristretto.Point
 
 	var cPlus1 ristretto.Scalar
 
 	tmpPubKey = pubKey
 
-	HTmpPubKey.Derive(tmpPubKey.Bytes())
-
 	//  L_j = s_j*G + c_j*P_j
 	L1.ScalarMultBase(&s)
 	L2.ScalarMult(&tmpPubKey, &c)
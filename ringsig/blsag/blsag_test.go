@@ -41,7 +41,45 @@ func TestRingSig(t *testing.T) {
 		}
 	}
 	assert.Equal(t, len(mixin)+1, len(found))
+}
+
+// Sign used to leave every ring key but the signers at the identity,
+// so that the signer was revealed and the mixin ignored
+func TestSignRingHoldsMixin(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+
+	mixin := randPoints(4)
+
+	for j := 0; j <= len(mixin); j++ {
+		rs := sign(msg, mixin, privKey, j, hashPubKey)
+
+		// the signer is inserted at j, the mixin keep their order
+		expected := append(append(append([]ristretto.Point{}, mixin[:j]...), pubKey), mixin[j:]...)
+		assert.Equal(t, len(expected), len(rs.PubKeys))
+		for i := range expected {
+			assert.True(t, expected[i].Equals(&rs.PubKeys[i]))
+		}
 
+		ok, err := VerifyChecked(msg, rs)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+
+	// signatures with the layout of the broken Sign are rejected
+	identities := make([]ristretto.Point, len(mixin))
+	for i := range identities {
+		identities[i].SetZero()
+	}
+	rs := sign(msg, identities, privKey, 2, hashPubKey)
+	ok, err := VerifyChecked(msg, rs)
+	assert.Equal(t, ErrZeroKey, err)
+	assert.False(t, ok)
+	assert.False(t, Verify(msg, rs))
 }
 
 func TestVerify(t *testing.T) {
@@ -72,6 +110,27 @@ func TestVerify(t *testing.T) {
 
 }
 
+func TestVerifyRejectsInvalidRings(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	// a ring of the signer alone
+	rs := Sign(msg, nil, privKey)
+	assert.False(t, Verify(msg, rs))
+	_, err := VerifyChecked(msg, rs)
+	assert.Equal(t, ErrRingSize, err)
+
+	// a ring holding the same key twice
+	mixin := randPoints(3)
+	mixin[2] = mixin[0]
+	rs = Sign(msg, mixin, privKey)
+	assert.False(t, Verify(msg, rs))
+	_, err = VerifyChecked(msg, rs)
+	assert.Equal(t, ErrDuplicateKey, err)
+}
+
 //https://stackoverflow.com/a/31832326/5203311
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
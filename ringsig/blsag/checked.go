@@ -0,0 +1,162 @@
+package blsag
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// MinRingSize is the smallest ring, including the signer, accepted by
+// SignChecked and VerifyChecked
+const MinRingSize = 2
+
+var (
+	// ErrRingSize is returned when the ring is smaller than MinRingSize or larger than MaxRingSize
+	ErrRingSize = errors.New("ring size is out of bounds")
+	// ErrMalformed is returned when the number of responses does not match the ring
+	ErrMalformed = errors.New("number of responses does not match the number of ring keys")
+	// ErrZeroKey is returned when a private key is zero or a ring key is the identity
+	ErrZeroKey = errors.New("keys cannot be zero or the identity")
+	// ErrDuplicateKey is returned when a key appears more than once in the ring
+	ErrDuplicateKey = errors.New("ring contains a duplicate key")
+	// ErrInvalidKeyImage is returned when the key image is the identity
+	ErrInvalidKeyImage = errors.New("key image cannot be the identity")
+	// ErrSignerIndex is returned when the signers key is not at the given index of the ring
+	ErrSignerIndex = errors.New("signers key is not at the given index of the ring")
+	// ErrInvalidSignature is returned when the ring of challenges does not close
+	ErrInvalidSignature = errors.New("signature is not valid for the message and the ring")
+)
+
+// SignChecked signs m with sK, hiding the signers public key at a random
+// position among the mixin. Unlike Sign, the ring is validated first
+func SignChecked(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) (RingSignature, error) {
+	return signChecked(m, mixin, sK, hashPubKey)
+}
+
+func signChecked(m []byte, mixin []ristretto.Point, sK ristretto.Scalar, hp hashToPoint) (RingSignature, error) {
+
+	if sK.IsNonZeroI() == 0 {
+		return RingSignature{}, ErrZeroKey
+	}
+
+	var pK ristretto.Point
+	pK.ScalarMultBase(&sK)
+
+	err := checkRing(append([]ristretto.Point{pK}, mixin...))
+	if err != nil {
+		return RingSignature{}, err
+	}
+
+	j, err := randomIndex(len(mixin) + 1)
+	if err != nil {
+		return RingSignature{}, err
+	}
+
+	return sign(m, mixin, sK, j, hp), nil
+}
+
+// randomIndex returns a uniformly random index in [0, n)
+func randomIndex(n int) (int, error) {
+	j, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(j.Int64()), nil
+}
+
+// VerifyChecked returns true if m was signed by a member of the ring.
+// Malformed signatures are reported with an error and never cause a panic
+func VerifyChecked(m []byte, ringsig RingSignature) (bool, error) {
+	return verifyChecked(m, ringsig, hashPubKey)
+}
+
+func verifyChecked(m []byte, ringsig RingSignature, hp hashToPoint) (bool, error) {
+
+	if len(ringsig.S) != len(ringsig.PubKeys) {
+		return false, ErrMalformed
+	}
+
+	err := checkRing(ringsig.PubKeys)
+	if err != nil {
+		return false, err
+	}
+
+	if isIdentity(ringsig.I) {
+		return false, ErrInvalidKeyImage
+	}
+
+	// c_i+1 = H(m, L_i, R_i) must close the ring at c_0
+	c := ringsig.C
+	for i := range ringsig.PubKeys {
+		c, _, _ = computeCLR(m, ringsig.I, ringsig.PubKeys[i], hp(ringsig.PubKeys[i]), ringsig.S[i], c)
+	}
+
+	if !c.Equals(&ringsig.C) {
+		return false, ErrInvalidSignature
+	}
+	return true, nil
+}
+
+// checkRing makes sure the ring is within bounds and only
+// holds distinct keys which are not the identity
+func checkRing(ring []ristretto.Point) error {
+
+	if len(ring) < MinRingSize || len(ring) > MaxRingSize {
+		return ErrRingSize
+	}
+
+	seen := make(map[[32]byte]struct{}, len(ring))
+	for i := range ring {
+		if isIdentity(ring[i]) {
+			return ErrZeroKey
+		}
+
+		var key [32]byte
+		ring[i].BytesInto(&key)
+		if _, ok := seen[key]; ok {
+			return ErrDuplicateKey
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// isIdentity returns true if p encodes to the identity. An uninitialised
+// point also encodes to the identity, so it is rejected as well
+func isIdentity(p ristretto.Point) bool {
+	var buf [32]byte
+	p.BytesInto(&buf)
+	return buf == [32]byte{}
+}
+
+// SignAt signs m with sK, whose public key must be at index j of the ring.
+// The ring is used as given, which lets several signers share one ring
+func SignAt(m []byte, ring []ristretto.Point, j int, sK ristretto.Scalar) (RingSignature, error) {
+
+	if sK.IsNonZeroI() == 0 {
+		return RingSignature{}, ErrZeroKey
+	}
+
+	err := checkRing(ring)
+	if err != nil {
+		return RingSignature{}, err
+	}
+
+	if j < 0 || j >= len(ring) {
+		return RingSignature{}, ErrSignerIndex
+	}
+
+	var pK ristretto.Point
+	pK.ScalarMultBase(&sK)
+	if !pK.Equals(&ring[j]) {
+		return RingSignature{}, ErrSignerIndex
+	}
+
+	mixin := make([]ristretto.Point, 0, len(ring)-1)
+	mixin = append(mixin, ring[:j]...)
+	mixin = append(mixin, ring[j+1:]...)
+
+	return sign(m, mixin, sK, j, hashPubKey), nil
+}
@@ -0,0 +1,145 @@
+package blsag
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerifyChecked(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	sig, err := SignChecked(msg, randPoints(7), privKey)
+	require.Nil(t, err)
+	assert.Equal(t, 8, len(sig.PubKeys))
+
+	ok, err := VerifyChecked(msg, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyChecked([]byte("something random"), sig)
+	assert.Equal(t, ErrInvalidSignature, err)
+	assert.False(t, ok)
+}
+
+func TestSignCheckedBadInput(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey, zero ristretto.Scalar
+	privKey.Rand()
+	zero.SetZero()
+
+	var pubKey, identity ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+	identity.SetZero()
+
+	mixin := randPoints(4)
+
+	cases := map[string]struct {
+		mixin   []ristretto.Point
+		privKey ristretto.Scalar
+		err     error
+	}{
+		"zero private key":    {mixin, zero, ErrZeroKey},
+		"no mixin":            {nil, privKey, ErrRingSize},
+		"ring too large":      {randPoints(MaxRingSize), privKey, ErrRingSize},
+		"identity mixin":      {append([]ristretto.Point{identity}, mixin...), privKey, ErrZeroKey},
+		"uninitialised mixin": {append([]ristretto.Point{{}}, mixin...), privKey, ErrZeroKey},
+		"duplicate mixin":     {append([]ristretto.Point{mixin[2]}, mixin...), privKey, ErrDuplicateKey},
+		"signer in mixin":     {append([]ristretto.Point{pubKey}, mixin...), privKey, ErrDuplicateKey},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := SignChecked(msg, c.mixin, c.privKey)
+			assert.Equal(t, c.err, err)
+		})
+	}
+}
+
+func TestVerifyCheckedMalformed(t *testing.T) {
+
+	tamper := map[string]struct {
+		fn  func(sig *RingSignature)
+		err error
+	}{
+		"missing response": {func(sig *RingSignature) {
+			sig.S = sig.S[1:]
+		}, ErrMalformed},
+		"extra response": {func(sig *RingSignature) {
+			sig.S = append(sig.S, sig.S[0])
+		}, ErrMalformed},
+		"empty": {func(sig *RingSignature) {
+			*sig = RingSignature{}
+		}, ErrRingSize},
+		"identity key image": {func(sig *RingSignature) {
+			sig.I.SetZero()
+		}, ErrInvalidKeyImage},
+		"identity ring key": {func(sig *RingSignature) {
+			sig.PubKeys[1].SetZero()
+		}, ErrZeroKey},
+		"duplicate ring key": {func(sig *RingSignature) {
+			sig.PubKeys[1] = sig.PubKeys[0]
+		}, ErrDuplicateKey},
+		"response": {func(sig *RingSignature) {
+			sig.S[2].Rand()
+		}, ErrInvalidSignature},
+	}
+
+	for name, c := range tamper {
+		t.Run(name, func(t *testing.T) {
+			msg := []byte("hello world")
+			sig := randSignature(msg, 4)
+			c.fn(&sig)
+
+			ok, err := VerifyChecked(msg, sig)
+			assert.Equal(t, c.err, err)
+			assert.False(t, ok)
+
+			// The legacy API must reject the signature without panicking
+			assert.NotPanics(t, func() {
+				assert.False(t, Verify(msg, sig))
+			})
+		})
+	}
+}
+
+func TestSignAt(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	ring := randPoints(6)
+	ring[4].ScalarMultBase(&privKey)
+
+	sig, err := SignAt(msg, ring, 4, privKey)
+	require.Nil(t, err)
+	assert.Equal(t, ring, sig.PubKeys)
+
+	ok, err := VerifyChecked(msg, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	for _, j := range []int{-1, 3, 6} {
+		_, err = SignAt(msg, ring, j, privKey)
+		assert.Equal(t, ErrSignerIndex, err)
+	}
+
+	ring[1] = ring[4]
+	_, err = SignAt(msg, ring, 4, privKey)
+	assert.Equal(t, ErrDuplicateKey, err)
+}
+
+func randPoints(n int) []ristretto.Point {
+	points := make([]ristretto.Point, n)
+	for i := range points {
+		points[i].Rand()
+	}
+	return points
+}
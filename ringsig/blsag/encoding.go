@@ -0,0 +1,275 @@
+package blsag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// EncodingV1 is the version of the binary encoding of a RingSignature
+const EncodingV1 uint8 = 1
+
+// MaxRingSize is the maximum number of ring members accepted when decoding a signature
+const MaxRingSize = 1 << 10
+
+// Encode writes the canonical binary encoding of the signature:
+// version | key image | c | uint32 n | n responses | uint32 n | n ring keys
+func (r *RingSignature) Encode(w io.Writer) error {
+
+	if len(r.S) > MaxRingSize || len(r.PubKeys) > MaxRingSize {
+		return fmt.Errorf("ring size exceeds the maximum of %d", MaxRingSize)
+	}
+	if len(r.S) != len(r.PubKeys) {
+		return errors.New("number of responses does not match the number of ring keys")
+	}
+
+	err := binary.Write(w, binary.BigEndian, EncodingV1)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, r.I.Bytes())
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, r.C.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(len(r.S)))
+	if err != nil {
+		return err
+	}
+	for i := range r.S {
+		err = binary.Write(w, binary.BigEndian, r.S[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(len(r.PubKeys)))
+	if err != nil {
+		return err
+	}
+	for i := range r.PubKeys {
+		err = binary.Write(w, binary.BigEndian, r.PubKeys[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a signature written with Encode. Points must be valid
+// ristretto encodings and scalars must be fully reduced
+func (r *RingSignature) Decode(rd io.Reader) error {
+
+	if r == nil {
+		return errors.New("struct is nil")
+	}
+
+	var version uint8
+	err := binary.Read(rd, binary.BigEndian, &version)
+	if err != nil {
+		return err
+	}
+	if version != EncodingV1 {
+		return fmt.Errorf("unsupported encoding version %d", version)
+	}
+
+	err = readerToPoint(rd, &r.I)
+	if err != nil {
+		return err
+	}
+	err = readerToScalar(rd, &r.C)
+	if err != nil {
+		return err
+	}
+
+	numResponses, err := readLength(rd)
+	if err != nil {
+		return err
+	}
+	r.S = make([]ristretto.Scalar, numResponses)
+	for i := range r.S {
+		err = readerToScalar(rd, &r.S[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	numKeys, err := readLength(rd)
+	if err != nil {
+		return err
+	}
+	if numKeys != numResponses {
+		return errors.New("number of responses does not match the number of ring keys")
+	}
+	r.PubKeys = make([]ristretto.Point, numKeys)
+	for i := range r.PubKeys {
+		err = readerToPoint(rd, &r.PubKeys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both signatures are the same
+func (r RingSignature) Equals(other RingSignature) bool {
+	if !r.I.Equals(&other.I) || !r.C.Equals(&other.C) {
+		return false
+	}
+	if len(r.S) != len(other.S) || len(r.PubKeys) != len(other.PubKeys) {
+		return false
+	}
+	for i := range r.S {
+		if !r.S[i].Equals(&other.S[i]) {
+			return false
+		}
+	}
+	for i := range r.PubKeys {
+		if !r.PubKeys[i].Equals(&other.PubKeys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonRingSignature is the JSON form of a RingSignature, with
+// every point and scalar as a hex string
+type jsonRingSignature struct {
+	KeyImage string   `json:"keyImage"`
+	C        string   `json:"c"`
+	S        []string `json:"s"`
+	PubKeys  []string `json:"pubKeys"`
+}
+
+// MarshalJSON encodes the signature as JSON, for debugging
+func (r RingSignature) MarshalJSON() ([]byte, error) {
+	js := jsonRingSignature{
+		KeyImage: hex.EncodeToString(r.I.Bytes()),
+		C:        hex.EncodeToString(r.C.Bytes()),
+		S:        make([]string, len(r.S)),
+		PubKeys:  make([]string, len(r.PubKeys)),
+	}
+	for i := range r.S {
+		js.S[i] = hex.EncodeToString(r.S[i].Bytes())
+	}
+	for i := range r.PubKeys {
+		js.PubKeys[i] = hex.EncodeToString(r.PubKeys[i].Bytes())
+	}
+	return json.Marshal(js)
+}
+
+// UnmarshalJSON decodes a signature written with MarshalJSON,
+// applying the same validation as Decode
+func (r *RingSignature) UnmarshalJSON(data []byte) error {
+
+	var js jsonRingSignature
+	err := json.Unmarshal(data, &js)
+	if err != nil {
+		return err
+	}
+
+	if len(js.S) > MaxRingSize || len(js.PubKeys) > MaxRingSize {
+		return fmt.Errorf("ring size exceeds the maximum of %d", MaxRingSize)
+	}
+	if len(js.S) != len(js.PubKeys) {
+		return errors.New("number of responses does not match the number of ring keys")
+	}
+
+	var sig RingSignature
+	err = hexToPoint(js.KeyImage, &sig.I)
+	if err != nil {
+		return err
+	}
+	err = hexToScalar(js.C, &sig.C)
+	if err != nil {
+		return err
+	}
+
+	sig.S = make([]ristretto.Scalar, len(js.S))
+	for i := range sig.S {
+		err = hexToScalar(js.S[i], &sig.S[i])
+		if err != nil {
+			return err
+		}
+	}
+	sig.PubKeys = make([]ristretto.Point, len(js.PubKeys))
+	for i := range sig.PubKeys {
+		err = hexToPoint(js.PubKeys[i], &sig.PubKeys[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	*r = sig
+	return nil
+}
+
+func hexToPoint(s string, p *ristretto.Point) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != 32 {
+		return fmt.Errorf("expected 32 bytes, got %d", len(b))
+	}
+	return readerToPoint(bytes.NewReader(b), p)
+}
+
+func hexToScalar(s string, sc *ristretto.Scalar) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != 32 {
+		return fmt.Errorf("expected 32 bytes, got %d", len(b))
+	}
+	return readerToScalar(bytes.NewReader(b), sc)
+}
+
+func readLength(r io.Reader) (uint32, error) {
+	var n uint32
+	err := binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return 0, err
+	}
+	if n > MaxRingSize {
+		return 0, fmt.Errorf("ring size %d exceeds the maximum of %d", n, MaxRingSize)
+	}
+	return n, nil
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
+
+func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	if !bytes.Equal(s.Bytes(), x[:]) {
+		return errors.New("scalar is not canonically encoded")
+	}
+	return nil
+}
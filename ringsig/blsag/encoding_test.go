@@ -0,0 +1,180 @@
+package blsag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// order of the ristretto group, which is a non canonical scalar encoding
+const groupOrder = "edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010"
+
+func TestEncodeDecode(t *testing.T) {
+	msg := []byte("hello world")
+	sig := randSignature(msg, 10)
+
+	buf := &bytes.Buffer{}
+	err := sig.Encode(buf)
+	require.Nil(t, err)
+
+	// version + I + C + 2 lengths + responses + ring keys
+	assert.Equal(t, 1+32+32+8+11*64, buf.Len())
+
+	var decodedSig RingSignature
+	err = decodedSig.Decode(buf)
+	require.Nil(t, err)
+	assert.Equal(t, 0, buf.Len())
+
+	assert.True(t, sig.Equals(decodedSig))
+	assert.True(t, Verify(msg, decodedSig))
+}
+
+func TestEquals(t *testing.T) {
+	sig := randSignature([]byte("hello world"), 4)
+
+	other := sig
+	other.S = append([]ristretto.Scalar{}, sig.S...)
+	assert.True(t, sig.Equals(other))
+
+	other.S[2].Rand()
+	assert.False(t, sig.Equals(other))
+
+	other.S = sig.S[1:]
+	assert.False(t, sig.Equals(other))
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	sig := randSignature([]byte("hello world"), 3)
+
+	buf := &bytes.Buffer{}
+	err := sig.Encode(buf)
+	require.Nil(t, err)
+	encoded := buf.Bytes()
+
+	order, _ := hex.DecodeString(groupOrder)
+	invalidPoint := bytes.Repeat([]byte{0xFF}, 32)
+
+	malform := map[string]func(b []byte) []byte{
+		"version": func(b []byte) []byte {
+			b[0] = EncodingV1 + 1
+			return b
+		},
+		"key image": func(b []byte) []byte {
+			copy(b[1:], invalidPoint)
+			return b
+		},
+		"non canonical challenge": func(b []byte) []byte {
+			copy(b[33:], order)
+			return b
+		},
+		"non canonical response": func(b []byte) []byte {
+			copy(b[69:], order)
+			return b
+		},
+		"ring key": func(b []byte) []byte {
+			copy(b[len(b)-32:], invalidPoint)
+			return b
+		},
+		"hostile ring size": func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[65:], 0xFFFFFFFF)
+			return b
+		},
+		"mismatched ring size": func(b []byte) []byte {
+			offset := 69 + 4*32
+			binary.BigEndian.PutUint32(b[offset:], 3)
+			return b
+		},
+		"truncated": func(b []byte) []byte {
+			return b[:len(b)-1]
+		},
+		"empty": func(b []byte) []byte {
+			return nil
+		},
+	}
+
+	for name, fn := range malform {
+		t.Run(name, func(t *testing.T) {
+			b := fn(append([]byte{}, encoded...))
+
+			var decodedSig RingSignature
+			err := decodedSig.Decode(bytes.NewReader(b))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestEncodeMismatchedLengths(t *testing.T) {
+	sig := randSignature([]byte("hello world"), 3)
+	sig.S = sig.S[1:]
+
+	err := sig.Encode(&bytes.Buffer{})
+	assert.NotNil(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	msg := []byte("hello world")
+	sig := randSignature(msg, 5)
+
+	data, err := json.Marshal(sig)
+	require.Nil(t, err)
+
+	var decodedSig RingSignature
+	err = json.Unmarshal(data, &decodedSig)
+	require.Nil(t, err)
+
+	assert.True(t, sig.Equals(decodedSig))
+	assert.True(t, Verify(msg, decodedSig))
+}
+
+func TestJSONMalformed(t *testing.T) {
+	sig := randSignature([]byte("hello world"), 2)
+
+	valid := hex.EncodeToString(sig.I.Bytes())
+	scalar := hex.EncodeToString(sig.C.Bytes())
+
+	malformed := map[string]jsonRingSignature{
+		"bad hex": {
+			KeyImage: "zz", C: scalar,
+		},
+		"short point": {
+			KeyImage: valid[:62], C: scalar,
+		},
+		"invalid point": {
+			KeyImage: hex.EncodeToString(bytes.Repeat([]byte{0xFF}, 32)), C: scalar,
+		},
+		"non canonical scalar": {
+			KeyImage: valid, C: groupOrder,
+		},
+		"mismatched ring size": {
+			KeyImage: valid, C: scalar, S: []string{scalar}, PubKeys: []string{valid, valid},
+		},
+	}
+
+	for name, js := range malformed {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(js)
+			require.Nil(t, err)
+
+			var decodedSig RingSignature
+			err = json.Unmarshal(data, &decodedSig)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func randSignature(msg []byte, numMixins int) RingSignature {
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	mixin := make([]ristretto.Point, numMixins)
+	for i := range mixin {
+		mixin[i].Rand()
+	}
+	return Sign(msg, mixin, privKey)
+}
@@ -0,0 +1,95 @@
+package blsag
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// tagDomain separates the tagged key image bases from the untagged ones,
+// so that an empty event does not reproduce the untagged key image
+var tagDomain = []byte("vosbor.blsag.event")
+
+// hashToPoint maps a ring key to the base of its key image
+type hashToPoint func(pubKey ristretto.Point) ristretto.Point
+
+// hashPubKey returns Hp(pk), the base of untagged key images
+func hashPubKey(pubKey ristretto.Point) ristretto.Point {
+	var h ristretto.Point
+	h.Derive(pubKey.Bytes())
+	return h
+}
+
+// hashEvent returns the function mapping pk to Hp(event || pk)
+func hashEvent(event []byte) hashToPoint {
+	prefix := new(bytes.Buffer)
+	prefix.Write(tagDomain)
+	binary.Write(prefix, binary.BigEndian, uint32(len(event)))
+	prefix.Write(event)
+
+	return func(pubKey ristretto.Point) ristretto.Point {
+		buf := bytes.NewBuffer(append([]byte{}, prefix.Bytes()...))
+		buf.Write(pubKey.Bytes())
+
+		var h ristretto.Point
+		h.Derive(buf.Bytes())
+		return h
+	}
+}
+
+// Link returns true if both signatures were created with the same key.
+// Tagged signatures only link when they were created for the same event
+func Link(a, b RingSignature) bool {
+	return a.I.Equals(&b.I)
+}
+
+// LinkedGroups returns the indices of the signatures sharing a key image,
+// one group per key image used more than once
+func LinkedGroups(sigs []RingSignature) [][]int {
+	indices := make(map[[32]byte][]int)
+	var order [][32]byte
+
+	for i := range sigs {
+		var key [32]byte
+		sigs[i].I.BytesInto(&key)
+		if _, ok := indices[key]; !ok {
+			order = append(order, key)
+		}
+		indices[key] = append(indices[key], i)
+	}
+
+	var groups [][]int
+	for _, key := range order {
+		if len(indices[key]) > 1 {
+			groups = append(groups, indices[key])
+		}
+	}
+	return groups
+}
+
+// TaggedKeyImage returns sK * Hp(event || pk), the key image
+// the owner of sK produces when signing for event
+func TaggedKeyImage(event []byte, sK ristretto.Scalar) ristretto.Point {
+	var pK ristretto.Point
+	pK.ScalarMultBase(&sK)
+
+	hPK := hashEvent(event)(pK)
+
+	var I ristretto.Point
+	I.ScalarMult(&hPK, &sK)
+	return I
+}
+
+// SignTagged signs m for an event such as a poll or an epoch. The key image is
+// sK * Hp(event || pk), so the signatures of a member are linkable within the
+// event and unlinkable across events. The event is not stored in the signature
+// and must be supplied to VerifyTagged
+func SignTagged(m, event []byte, mixin []ristretto.Point, sK ristretto.Scalar) (RingSignature, error) {
+	return signChecked(m, mixin, sK, hashEvent(event))
+}
+
+// VerifyTagged returns true if m was signed for event by a member of the ring
+func VerifyTagged(m, event []byte, ringsig RingSignature) (bool, error) {
+	return verifyChecked(m, ringsig, hashEvent(event))
+}
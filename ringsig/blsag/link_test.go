@@ -0,0 +1,118 @@
+package blsag
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey, otherKey ristretto.Scalar
+	privKey.Rand()
+	otherKey.Rand()
+
+	a, err := SignChecked(msg, randPoints(4), privKey)
+	require.Nil(t, err)
+	b, err := SignChecked([]byte("another message"), randPoints(6), privKey)
+	require.Nil(t, err)
+	c, err := SignChecked(msg, randPoints(4), otherKey)
+	require.Nil(t, err)
+
+	assert.True(t, Link(a, b))
+	assert.False(t, Link(a, c))
+
+	assert.Equal(t, [][]int{{0, 2}}, LinkedGroups([]RingSignature{a, c, b}))
+	assert.Nil(t, LinkedGroups([]RingSignature{a, c}))
+}
+
+func TestTagged(t *testing.T) {
+	msg := []byte("yes")
+	event := []byte("poll 42")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	sig, err := SignTagged(msg, event, randPoints(5), privKey)
+	require.Nil(t, err)
+	keyImage := TaggedKeyImage(event, privKey)
+	assert.True(t, keyImage.Equals(&sig.I))
+
+	ok, err := VerifyTagged(msg, event, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// The event is part of the statement, not the signature
+	ok, err = VerifyTagged(msg, []byte("poll 43"), sig)
+	assert.Equal(t, ErrInvalidSignature, err)
+	assert.False(t, ok)
+
+	ok, err = VerifyChecked(msg, sig)
+	assert.Equal(t, ErrInvalidSignature, err)
+	assert.False(t, ok)
+}
+
+func TestTaggedLinkability(t *testing.T) {
+	event := []byte("epoch 7")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	// Two votes in the same poll link, even over different rings
+	a, err := SignTagged([]byte("yes"), event, randPoints(5), privKey)
+	require.Nil(t, err)
+	b, err := SignTagged([]byte("no"), event, randPoints(9), privKey)
+	require.Nil(t, err)
+	assert.True(t, Link(a, b))
+
+	// Votes in another poll and untagged signatures do not
+	c, err := SignTagged([]byte("yes"), []byte("epoch 8"), randPoints(5), privKey)
+	require.Nil(t, err)
+	assert.False(t, Link(a, c))
+
+	d, err := SignChecked([]byte("yes"), randPoints(5), privKey)
+	require.Nil(t, err)
+	assert.False(t, Link(a, d))
+
+	// An empty event is still separated from untagged signatures
+	e, err := SignTagged([]byte("yes"), nil, randPoints(5), privKey)
+	require.Nil(t, err)
+	assert.False(t, Link(d, e))
+}
+
+func TestResponsesAreRandom(t *testing.T) {
+	// No response may be left at zero, as it would reveal the signer is elsewhere
+	for i := 0; i < 10; i++ {
+		sig := randSignature([]byte("hello world"), 3)
+		for j := range sig.S {
+			assert.NotEqual(t, 0, sig.S[j].IsNonZeroI())
+		}
+	}
+}
+
+// sign used to leave the response of the first member at zero whenever
+// the signer was elsewhere in the ring, revealing that it was not there
+func TestFirstResponseIsRandom(t *testing.T) {
+	msg := []byte("hello world")
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+	mixin := randPoints(3)
+
+	for j := 0; j <= len(mixin); j++ {
+		a := sign(msg, mixin, privKey, j, hashPubKey)
+		b := sign(msg, mixin, privKey, j, hashPubKey)
+
+		for i := range a.S {
+			assert.Equal(t, int32(1), a.S[i].IsNonZeroI())
+		}
+		assert.False(t, a.S[0].Equals(&b.S[0]))
+
+		ok, err := VerifyChecked(msg, a)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,331 @@
+// Package threshold implements t-of-n ring signatures.
+//
+// A threshold signature is a set of bLSAG signatures over one canonical ring,
+// each created by a different member. As key images are bound to the key of
+// the signer, the verifier counts the distinct key images to learn how many
+// members approved the message, without learning which members they are.
+package threshold
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/ringsig/blsag"
+)
+
+// EncodingV1 is the version of the binary encoding of a Signature
+const EncodingV1 uint8 = 1
+
+var domain = []byte("vosbor.threshold.v1")
+
+// Signature holds the approvals of the members of a ring
+type Signature struct {
+	// Ring is the canonical ring every approval is made over
+	Ring []ristretto.Point
+
+	// Approvals are the bLSAG signatures of the members
+	Approvals []blsag.RingSignature
+}
+
+// Canonical returns a copy of the ring sorted by the encoding of the keys.
+// Every member must use the canonical ring so that approvals can be combined
+func Canonical(ring []ristretto.Point) []ristretto.Point {
+	canonical := append([]ristretto.Point{}, ring...)
+	sort.Slice(canonical, func(i, j int) bool {
+		return bytes.Compare(canonical[i].Bytes(), canonical[j].Bytes()) < 0
+	})
+	return canonical
+}
+
+// Approve creates the approval of m by the owner of sK, a member of the ring
+func Approve(m []byte, ring []ristretto.Point, sK ristretto.Scalar) (blsag.RingSignature, error) {
+	ring = Canonical(ring)
+
+	var pK ristretto.Point
+	pK.ScalarMultBase(&sK)
+
+	for j := range ring {
+		if ring[j].Equals(&pK) {
+			return blsag.SignAt(message(m, ring), ring, j, sK)
+		}
+	}
+	return blsag.RingSignature{}, errors.New("private key does not belong to a member of the ring")
+}
+
+// Combine collects the approvals of m into a threshold signature.
+// Every approval is verified, and approvals from the same member are rejected
+func Combine(m []byte, ring []ristretto.Point, approvals []blsag.RingSignature) (*Signature, error) {
+	sig := &Signature{
+		Ring:      Canonical(ring),
+		Approvals: approvals,
+	}
+
+	_, err := sig.verify(m)
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// Sign creates a threshold signature of m approved by every private key given
+func Sign(m []byte, ring []ristretto.Point, privKeys []ristretto.Scalar) (*Signature, error) {
+	approvals := make([]blsag.RingSignature, 0, len(privKeys))
+	for i := range privKeys {
+		approval, err := Approve(m, ring, privKeys[i])
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return Combine(m, ring, approvals)
+}
+
+// Verify returns true if at least t distinct members of the ring approved m
+func Verify(m []byte, ring []ristretto.Point, t int, sig *Signature) (bool, error) {
+
+	if t < 1 {
+		return false, errors.New("threshold must be at least one")
+	}
+	if sig == nil {
+		return false, errors.New("signature is nil")
+	}
+
+	canonical := Canonical(ring)
+	if !equalRings(canonical, sig.Ring) {
+		return false, errors.New("signature is not made over the given ring")
+	}
+
+	signers, err := sig.verify(m)
+	if err != nil {
+		return false, err
+	}
+	if signers < t {
+		return false, fmt.Errorf("signature has %d approvals, %d are required", signers, t)
+	}
+	return true, nil
+}
+
+// KeyImages returns the key images of the approving members
+func (sig *Signature) KeyImages() []ristretto.Point {
+	keyImages := make([]ristretto.Point, len(sig.Approvals))
+	for i := range sig.Approvals {
+		keyImages[i] = sig.Approvals[i].I
+	}
+	return keyImages
+}
+
+// verify checks every approval and returns the number of distinct signers
+func (sig *Signature) verify(m []byte) (int, error) {
+
+	if len(sig.Approvals) == 0 {
+		return 0, errors.New("signature has no approvals")
+	}
+	if len(sig.Approvals) > len(sig.Ring) {
+		return 0, errors.New("there cannot be more approvals than members")
+	}
+
+	msg := message(m, sig.Ring)
+	if len(blsag.LinkedGroups(sig.Approvals)) != 0 {
+		return 0, errors.New("a member approved more than once")
+	}
+
+	for i := range sig.Approvals {
+		if !equalRings(sig.Ring, sig.Approvals[i].PubKeys) {
+			return 0, fmt.Errorf("approval %d is not made over the ring", i)
+		}
+
+		ok, err := blsag.VerifyChecked(msg, sig.Approvals[i])
+		if !ok || err != nil {
+			return 0, fmt.Errorf("approval %d is invalid: %v", i, err)
+		}
+	}
+	return len(sig.Approvals), nil
+}
+
+// message binds m to the threshold domain and the ring,
+// so that an approval cannot be used as a plain bLSAG signature
+func message(m []byte, ring []ristretto.Point) []byte {
+	h := sha512.New()
+	h.Write(domain)
+	binary.Write(h, binary.BigEndian, uint32(len(ring)))
+	for i := range ring {
+		h.Write(ring[i].Bytes())
+	}
+	h.Write(m)
+	return h.Sum(nil)
+}
+
+func equalRings(a, b []ristretto.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode writes the signature. The ring is written once and shared by the approvals:
+// version | uint32 n | n ring keys | uint32 k | k * (key image | c | n responses)
+func (sig *Signature) Encode(w io.Writer) error {
+
+	n := len(sig.Ring)
+	if n > blsag.MaxRingSize || len(sig.Approvals) > n {
+		return errors.New("signature exceeds the maximum size")
+	}
+	for i := range sig.Approvals {
+		if len(sig.Approvals[i].S) != n {
+			return errors.New("number of responses does not match the ring")
+		}
+	}
+
+	err := binary.Write(w, binary.BigEndian, EncodingV1)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(n))
+	if err != nil {
+		return err
+	}
+	for i := range sig.Ring {
+		err = binary.Write(w, binary.BigEndian, sig.Ring[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(len(sig.Approvals)))
+	if err != nil {
+		return err
+	}
+	for _, approval := range sig.Approvals {
+		err = binary.Write(w, binary.BigEndian, approval.I.Bytes())
+		if err != nil {
+			return err
+		}
+		err = binary.Write(w, binary.BigEndian, approval.C.Bytes())
+		if err != nil {
+			return err
+		}
+		for i := range approval.S {
+			err = binary.Write(w, binary.BigEndian, approval.S[i].Bytes())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Decode reads a signature written with Encode
+func (sig *Signature) Decode(r io.Reader) error {
+
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return err
+	}
+	if version != EncodingV1 {
+		return fmt.Errorf("unsupported encoding version %d", version)
+	}
+
+	var n, k uint32
+	err = binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return err
+	}
+	if n > blsag.MaxRingSize {
+		return fmt.Errorf("ring size %d exceeds the maximum of %d", n, blsag.MaxRingSize)
+	}
+
+	sig.Ring = make([]ristretto.Point, n)
+	for i := range sig.Ring {
+		err = readerToPoint(r, &sig.Ring[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = binary.Read(r, binary.BigEndian, &k)
+	if err != nil {
+		return err
+	}
+	if k > n {
+		return errors.New("there cannot be more approvals than members")
+	}
+
+	sig.Approvals = make([]blsag.RingSignature, k)
+	for i := range sig.Approvals {
+		approval := &sig.Approvals[i]
+		approval.PubKeys = sig.Ring
+
+		err = readerToPoint(r, &approval.I)
+		if err != nil {
+			return err
+		}
+		err = readerToScalar(r, &approval.C)
+		if err != nil {
+			return err
+		}
+		approval.S = make([]ristretto.Scalar, n)
+		for j := range approval.S {
+			err = readerToScalar(r, &approval.S[j])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both signatures are the same
+func (sig *Signature) Equals(other *Signature) bool {
+	if !equalRings(sig.Ring, other.Ring) || len(sig.Approvals) != len(other.Approvals) {
+		return false
+	}
+	for i := range sig.Approvals {
+		if !sig.Approvals[i].Equals(other.Approvals[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
+
+func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	if !bytes.Equal(s.Bytes(), x[:]) {
+		return errors.New("scalar is not canonically encoded")
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package threshold
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/ringsig/blsag"
+)
+
+func TestThreshold(t *testing.T) {
+	msg := []byte("approve proposal 7")
+
+	for _, n := range []int{5, 16, 64} {
+		for threshold := 1; threshold <= 5; threshold++ {
+			ring, privKeys := generateRing(n, threshold)
+
+			sig, err := Sign(msg, ring, privKeys)
+			require.Nil(t, err)
+			assert.Equal(t, threshold, len(sig.KeyImages()))
+
+			ok, err := Verify(msg, ring, threshold, sig)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+
+			// The signature falls short of a higher threshold
+			ok, err = Verify(msg, ring, threshold+1, sig)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		}
+	}
+}
+
+func TestApproveCombine(t *testing.T) {
+	msg := []byte("approve proposal 7")
+	ring, privKeys := generateRing(10, 3)
+
+	// Members approve independently, in any ring order
+	var approvals []blsag.RingSignature
+	for i := range privKeys {
+		shuffled := append([]ristretto.Point{}, ring[i:]...)
+		shuffled = append(shuffled, ring[:i]...)
+
+		approval, err := Approve(msg, shuffled, privKeys[i])
+		require.Nil(t, err)
+		approvals = append(approvals, approval)
+	}
+
+	sig, err := Combine(msg, ring, approvals)
+	require.Nil(t, err)
+
+	ok, err := Verify(msg, ring, 3, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDuplicateApprovals(t *testing.T) {
+	msg := []byte("approve proposal 7")
+	ring, privKeys := generateRing(10, 2)
+
+	// A single member cannot approve twice
+	_, err := Sign(msg, ring, []ristretto.Scalar{privKeys[0], privKeys[1], privKeys[0]})
+	assert.NotNil(t, err)
+
+	sig, err := Sign(msg, ring, privKeys)
+	require.Nil(t, err)
+
+	sig.Approvals = append(sig.Approvals, sig.Approvals[0])
+	ok, err := Verify(msg, ring, 3, sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestBadThresholdSig(t *testing.T) {
+
+	tamper := map[string]func(msg *[]byte, ring []ristretto.Point, sig *Signature){
+		"message": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			*msg = []byte("something random")
+		},
+		"ring": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			ring[0].Rand()
+		},
+		"signature ring": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.Ring[0].Rand()
+		},
+		"approval ring": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.Approvals[1].PubKeys = append([]ristretto.Point{}, sig.Ring...)
+			sig.Approvals[1].PubKeys[3].Rand()
+		},
+		"key image": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.Approvals[0].I.Rand()
+		},
+		"no approvals": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.Approvals = nil
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			msg := []byte("approve proposal 7")
+			ring, privKeys := generateRing(8, 2)
+
+			sig, err := Sign(msg, ring, privKeys)
+			require.Nil(t, err)
+
+			fn(&msg, ring, sig)
+
+			ok, err := Verify(msg, ring, 1, sig)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestNotAMember(t *testing.T) {
+	ring, _ := generateRing(8, 1)
+
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	_, err := Approve([]byte("approve proposal 7"), ring, privKey)
+	assert.NotNil(t, err)
+}
+
+func TestApprovalIsNotABLSAG(t *testing.T) {
+	msg := []byte("approve proposal 7")
+	ring, privKeys := generateRing(8, 1)
+
+	approval, err := Approve(msg, ring, privKeys[0])
+	require.Nil(t, err)
+
+	ok, err := blsag.VerifyChecked(msg, approval)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	msg := []byte("approve proposal 7")
+	ring, privKeys := generateRing(32, 4)
+
+	sig, err := Sign(msg, ring, privKeys)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf)
+	require.Nil(t, err)
+
+	// version + 2 lengths + ring + approvals
+	assert.Equal(t, 1+8+32*32+4*(64+32*32), buf.Len())
+
+	decodedSig := &Signature{}
+	err = decodedSig.Decode(buf)
+	require.Nil(t, err)
+	assert.Equal(t, 0, buf.Len())
+	assert.True(t, sig.Equals(decodedSig))
+
+	ok, err := Verify(msg, ring, 4, decodedSig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	ring, privKeys := generateRing(4, 2)
+
+	sig, err := Sign([]byte("approve proposal 7"), ring, privKeys)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf)
+	require.Nil(t, err)
+	encoded := buf.Bytes()
+
+	malform := map[string]func(b []byte) []byte{
+		"version": func(b []byte) []byte {
+			b[0] = EncodingV1 + 1
+			return b
+		},
+		"hostile ring size": func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[1:], 0xFFFFFFFF)
+			return b
+		},
+		"too many approvals": func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[5+4*32:], 5)
+			return b
+		},
+		"invalid ring key": func(b []byte) []byte {
+			copy(b[5:], bytes.Repeat([]byte{0xFF}, 32))
+			return b
+		},
+		"truncated": func(b []byte) []byte {
+			return b[:len(b)-1]
+		},
+	}
+
+	for name, fn := range malform {
+		t.Run(name, func(t *testing.T) {
+			b := fn(append([]byte{}, encoded...))
+			err := (&Signature{}).Decode(bytes.NewReader(b))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+// generateRing returns a ring of n keys and the private keys
+// of the first t members
+func generateRing(n, t int) ([]ristretto.Point, []ristretto.Scalar) {
+	ring := make([]ristretto.Point, n)
+	privKeys := make([]ristretto.Scalar, t)
+
+	for i := range ring {
+		var privKey ristretto.Scalar
+		privKey.Rand()
+		ring[i].ScalarMultBase(&privKey)
+
+		if i < t {
+			privKeys[i] = privKey
+		}
+	}
+	return ring, privKeys
+}
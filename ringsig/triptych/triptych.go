@@ -0,0 +1,607 @@
+// Package triptych implements a logarithmic size linkable ring signature.
+//
+// The signature is a Groth-Kohlweiss one-out-of-many proof, as used by Triptych,
+// over the ring of public keys P_k and over the ring of their hashes Hp(P_k).
+// Both sums collapse to the signers entry using the same bit commitments, after
+// which a sigma protocol proves knowledge of x such that P_l = x * G and
+// I = x * Hp(P_l). The key image I therefore has the same format as the
+// key images produced by mlsag.CalculateKeyImage.
+//
+// The size of a signature grows with the logarithm of the ring size,
+// which must be a power of two.
+package triptych
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/mlsag"
+	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+// MaxRingSize is the largest ring which can be signed or verified
+const MaxRingSize = 1 << maxM
+
+// maxM is the log2 of the largest ring
+const maxM = 12
+
+var (
+	genData         = []byte("vosbor.triptych.v1")
+	transcriptLabel = []byte("vosbor.triptych")
+)
+
+// Signature is a Triptych style linkable ring signature
+type Signature struct {
+	// KeyImage is the key image of the signers key
+	KeyImage ristretto.Point
+
+	// commitments to the bits of the signers index
+	A, B, C, D ristretto.Point
+
+	// X, Y and V are the lower order terms of the collapsed rings
+	X, Y, V []ristretto.Point
+
+	// f are the responses to the bit commitments
+	f []ristretto.Scalar
+
+	zA, zC ristretto.Scalar
+
+	// c, s1 and s2 prove knowledge of the signers key
+	c, s1, s2 ristretto.Scalar
+}
+
+// generators holds the bases shared by the prover and the verifier
+type generators struct {
+	// bases for the matrix commitments, two for each bit
+	bases []ristretto.Point
+	// blinding base of the matrix commitments
+	blind ristretto.Point
+	// F is the base used to blind the collapsed rings
+	F ristretto.Point
+}
+
+func newGenerators(m int) *generators {
+	ped := pedersen.New(genData)
+	ped.BaseVector.Compute(uint32(2 * m))
+
+	return &generators{
+		bases: ped.BaseVector.Bases,
+		blind: ped.BlindPoint,
+		F:     ped.BasePoint,
+	}
+}
+
+// commit computes r * blind + sum(matrix[j][i] * bases[2j+i])
+func (g *generators) commit(matrix [][2]ristretto.Scalar, r ristretto.Scalar) ristretto.Point {
+	var sum ristretto.Point
+	sum.ScalarMult(&g.blind, &r)
+
+	for j := range matrix {
+		for i := 0; i < 2; i++ {
+			var p ristretto.Point
+			p.ScalarMult(&g.bases[2*j+i], &matrix[j][i])
+			sum.Add(&sum, &p)
+		}
+	}
+	return sum
+}
+
+// Sign creates a signature for msg, proving knowledge of the private
+// key of ring[index] without revealing index
+func Sign(msg []byte, ring []ristretto.Point, index int, privKey ristretto.Scalar) (*Signature, error) {
+
+	m, err := ringExponent(len(ring))
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(ring) {
+		return nil, errors.New("signer index is out of range")
+	}
+
+	if privKey.IsNonZeroI() == 0 {
+		return nil, errors.New("private key cannot be zero")
+	}
+
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+	if !pubKey.Equals(&ring[index]) {
+		return nil, errors.New("private key does not match the key at the signer index")
+	}
+
+	gens := newGenerators(m)
+	hashes := hashRing(ring)
+
+	sig := &Signature{
+		KeyImage: mlsag.CalculateKeyImage(privKey, pubKey),
+	}
+
+	// sigma holds the bits of the index, a the random masks
+	sigma := make([][2]ristretto.Scalar, m)
+	a := make([][2]ristretto.Scalar, m)
+	cMatrix := make([][2]ristretto.Scalar, m)
+	dMatrix := make([][2]ristretto.Scalar, m)
+
+	var one ristretto.Scalar
+	one.SetOne()
+
+	for j := 0; j < m; j++ {
+		bit := (index >> uint(j)) & 1
+		sigma[j][bit].SetOne()
+		sigma[j][1-bit].SetZero()
+
+		a[j][1].Rand()
+		a[j][0].Neg(&a[j][1])
+
+		for i := 0; i < 2; i++ {
+			// c = a * (1 - 2 * sigma)
+			var t ristretto.Scalar
+			t.Add(&sigma[j][i], &sigma[j][i])
+			t.Sub(&one, &t)
+			cMatrix[j][i].Mul(&a[j][i], &t)
+
+			// d = -a^2
+			dMatrix[j][i].Square(&a[j][i])
+			dMatrix[j][i].Neg(&dMatrix[j][i])
+		}
+	}
+
+	var rA, rB, rC, rD ristretto.Scalar
+	rA.Rand()
+	rB.Rand()
+	rC.Rand()
+	rD.Rand()
+
+	sig.A = gens.commit(a, rA)
+	sig.B = gens.commit(sigma, rB)
+	sig.C = gens.commit(cMatrix, rC)
+	sig.D = gens.commit(dMatrix, rD)
+
+	coeffs := ringCoefficients(sigma, a, len(ring))
+
+	rho := make([]ristretto.Scalar, m)
+	rhoH := make([]ristretto.Scalar, m)
+	sig.X = make([]ristretto.Point, m)
+	sig.Y = make([]ristretto.Point, m)
+	sig.V = make([]ristretto.Point, m)
+
+	for j := 0; j < m; j++ {
+		rho[j].Rand()
+		rhoH[j].Rand()
+
+		// X_j = sum(p_k,j * P_k) + rho_j * F
+		// Y_j = sum(p_k,j * Hp(P_k)) + rhoH_j * F
+		sig.X[j].ScalarMult(&gens.F, &rho[j])
+		sig.Y[j].ScalarMult(&gens.F, &rhoH[j])
+
+		for k := range ring {
+			var p ristretto.Point
+			p.ScalarMult(&ring[k], &coeffs[k][j])
+			sig.X[j].Add(&sig.X[j], &p)
+
+			p.ScalarMult(&hashes[k], &coeffs[k][j])
+			sig.Y[j].Add(&sig.Y[j], &p)
+		}
+
+		// V_j = -x * rhoH_j * F
+		var v ristretto.Scalar
+		v.Mul(&privKey, &rhoH[j])
+		v.Neg(&v)
+		sig.V[j].ScalarMult(&gens.F, &v)
+	}
+
+	t := newTranscript(msg, ring, sig)
+	xi := t.ChallengeScalar([]byte("xi"))
+	xiPowers := powers(xi, m+1)
+
+	sig.f = make([]ristretto.Scalar, m)
+	for j := 0; j < m; j++ {
+		sig.f[j].MulAdd(&sigma[j][1], &xi, &a[j][1])
+	}
+
+	sig.zA.MulAdd(&rB, &xi, &rA)
+	sig.zC.MulAdd(&rC, &xi, &rD)
+
+	// After collapsing the rings the verifier obtains
+	// collapsedP = xi^m * P_l - rho(xi) * F
+	// collapsedH = xi^m * Hp(P_l) - rhoH(xi) * F
+	var rhoXi, rhoHXi ristretto.Scalar
+	rhoXi.SetZero()
+	rhoHXi.SetZero()
+	for j := 0; j < m; j++ {
+		rhoXi.MulAdd(&rho[j], &xiPowers[j], &rhoXi)
+		rhoHXi.MulAdd(&rhoH[j], &xiPowers[j], &rhoHXi)
+	}
+
+	var collapsedH, p ristretto.Point
+	collapsedH.ScalarMult(&hashes[index], &xiPowers[m])
+	p.ScalarMult(&gens.F, &rhoHXi)
+	collapsedH.Sub(&collapsedH, &p)
+
+	// Prove knowledge of x and w such that
+	// collapsedP = x * (xi^m * G) + w * F and x * collapsedH = xi^m * I + sum(xi^j * V_j)
+	var w ristretto.Scalar
+	w.Neg(&rhoXi)
+
+	var k1, k2 ristretto.Scalar
+	k1.Rand()
+	k2.Rand()
+
+	var K1, K2 ristretto.Point
+	var k1Xi ristretto.Scalar
+	k1Xi.Mul(&k1, &xiPowers[m])
+	K1.ScalarMultBase(&k1Xi)
+	p.ScalarMult(&gens.F, &k2)
+	K1.Add(&K1, &p)
+	K2.ScalarMult(&collapsedH, &k1)
+
+	appendResponses(t, sig)
+	sig.c = sigmaChallenge(t, K1, K2)
+
+	sig.s1.Mul(&sig.c, &privKey)
+	sig.s1.Sub(&k1, &sig.s1)
+	sig.s2.Mul(&sig.c, &w)
+	sig.s2.Sub(&k2, &sig.s2)
+
+	return sig, nil
+}
+
+// Verify returns true if the signature of msg was created by
+// the owner of one of the keys in ring
+func Verify(msg []byte, ring []ristretto.Point, sig *Signature) (bool, error) {
+
+	m, err := ringExponent(len(ring))
+	if err != nil {
+		return false, err
+	}
+
+	if sig == nil || len(sig.X) != m || len(sig.Y) != m || len(sig.V) != m || len(sig.f) != m {
+		return false, errors.New("signature does not match the size of the ring")
+	}
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if sig.KeyImage.Equals(&zero) {
+		return false, errors.New("key image cannot be the identity")
+	}
+
+	gens := newGenerators(m)
+
+	t := newTranscript(msg, ring, sig)
+	xi := t.ChallengeScalar([]byte("xi"))
+	xiPowers := powers(xi, m+1)
+
+	// f_j,1 is given by the prover and f_j,0 = xi - f_j,1
+	f := make([][2]ristretto.Scalar, m)
+	fSq := make([][2]ristretto.Scalar, m)
+	for j := 0; j < m; j++ {
+		f[j][1] = sig.f[j]
+		f[j][0].Sub(&xi, &sig.f[j])
+
+		for i := 0; i < 2; i++ {
+			// f * (xi - f)
+			var t ristretto.Scalar
+			t.Sub(&xi, &f[j][i])
+			fSq[j][i].Mul(&f[j][i], &t)
+		}
+	}
+
+	// A + xi * B = Com(f, zA)
+	var lhs, p ristretto.Point
+	lhs.PublicScalarMult(&sig.B, &xi)
+	lhs.Add(&lhs, &sig.A)
+	rhs := gens.commit(f, sig.zA)
+	if !lhs.Equals(&rhs) {
+		return false, errors.New("bit commitments A and B do not open to f")
+	}
+
+	// xi * C + D = Com(f * (xi - f), zC)
+	lhs.PublicScalarMult(&sig.C, &xi)
+	lhs.Add(&lhs, &sig.D)
+	rhs = gens.commit(fSq, sig.zC)
+	if !lhs.Equals(&rhs) {
+		return false, errors.New("bit commitments C and D are not well formed")
+	}
+
+	// Collapse both rings with p_k(xi) = prod(f_j,k_j)
+	hashes := hashRing(ring)
+
+	var collapsedP, collapsedH ristretto.Point
+	collapsedP.SetZero()
+	collapsedH.SetZero()
+
+	for k := range ring {
+		var pk ristretto.Scalar
+		pk.SetOne()
+		for j := 0; j < m; j++ {
+			pk.Mul(&pk, &f[j][(k>>uint(j))&1])
+		}
+
+		p.PublicScalarMult(&ring[k], &pk)
+		collapsedP.Add(&collapsedP, &p)
+
+		p.PublicScalarMult(&hashes[k], &pk)
+		collapsedH.Add(&collapsedH, &p)
+	}
+
+	// R = xi^m * I + sum(xi^j * V_j)
+	var R ristretto.Point
+	R.PublicScalarMult(&sig.KeyImage, &xiPowers[m])
+
+	for j := 0; j < m; j++ {
+		p.PublicScalarMult(&sig.X[j], &xiPowers[j])
+		collapsedP.Sub(&collapsedP, &p)
+
+		p.PublicScalarMult(&sig.Y[j], &xiPowers[j])
+		collapsedH.Sub(&collapsedH, &p)
+
+		p.PublicScalarMult(&sig.V[j], &xiPowers[j])
+		R.Add(&R, &p)
+	}
+
+	// K1 = s1 * xi^m * G + s2 * F + c * collapsedP
+	var K1, K2 ristretto.Point
+	var s1Xi ristretto.Scalar
+	s1Xi.Mul(&sig.s1, &xiPowers[m])
+	K1.PublicScalarMultBase(&s1Xi)
+	p.PublicScalarMult(&gens.F, &sig.s2)
+	K1.Add(&K1, &p)
+	p.PublicScalarMult(&collapsedP, &sig.c)
+	K1.Add(&K1, &p)
+
+	// K2 = s1 * collapsedH + c * R
+	K2.PublicScalarMult(&collapsedH, &sig.s1)
+	p.PublicScalarMult(&R, &sig.c)
+	K2.Add(&K2, &p)
+
+	appendResponses(t, sig)
+	c := sigmaChallenge(t, K1, K2)
+
+	if !c.Equals(&sig.c) {
+		return false, errors.New("signature challenge does not match")
+	}
+	return true, nil
+}
+
+// Link returns true if both signatures were created with the same key
+func Link(a, b *Signature) bool {
+	return a.KeyImage.Equals(&b.KeyImage)
+}
+
+// ringCoefficients computes the coefficients of the polynomials
+// p_k(x) = prod((sigma_j,k_j * x) + a_j,k_j) for every member k
+func ringCoefficients(sigma, a [][2]ristretto.Scalar, n int) [][]ristretto.Scalar {
+	m := len(sigma)
+	coeffs := make([][]ristretto.Scalar, n)
+
+	for k := 0; k < n; k++ {
+		poly := make([]ristretto.Scalar, m+1)
+		poly[0].SetOne()
+		for i := 1; i <= m; i++ {
+			poly[i].SetZero()
+		}
+
+		for j := 0; j < m; j++ {
+			bit := (k >> uint(j)) & 1
+			s, c := sigma[j][bit], a[j][bit]
+
+			// multiply poly by (s * x + c)
+			for d := j + 1; d > 0; d-- {
+				var t ristretto.Scalar
+				t.Mul(&poly[d], &c)
+				poly[d].MulAdd(&poly[d-1], &s, &t)
+			}
+			poly[0].Mul(&poly[0], &c)
+		}
+		coeffs[k] = poly
+	}
+	return coeffs
+}
+
+func newTranscript(msg []byte, ring []ristretto.Point, sig *Signature) *fiatshamir.Transcript {
+	t := fiatshamir.NewTranscript(transcriptLabel)
+	t.AppendMessage([]byte("msg"), msg)
+
+	t.AppendUint64([]byte("members"), uint64(len(ring)))
+	for i := range ring {
+		t.AppendPoint([]byte("pubkey"), ring[i])
+	}
+	t.AppendPoint([]byte("key-image"), sig.KeyImage)
+
+	t.AppendPoint([]byte("A"), sig.A)
+	t.AppendPoint([]byte("B"), sig.B)
+	t.AppendPoint([]byte("C"), sig.C)
+	t.AppendPoint([]byte("D"), sig.D)
+
+	for j := range sig.X {
+		t.AppendPoint([]byte("X"), sig.X[j])
+		t.AppendPoint([]byte("Y"), sig.Y[j])
+		t.AppendPoint([]byte("V"), sig.V[j])
+	}
+	return t
+}
+
+func appendResponses(t *fiatshamir.Transcript, sig *Signature) {
+	for j := range sig.f {
+		t.AppendScalar([]byte("f"), sig.f[j])
+	}
+	t.AppendScalar([]byte("zA"), sig.zA)
+	t.AppendScalar([]byte("zC"), sig.zC)
+}
+
+func sigmaChallenge(t *fiatshamir.Transcript, K1, K2 ristretto.Point) ristretto.Scalar {
+	t.AppendPoint([]byte("K1"), K1)
+	t.AppendPoint([]byte("K2"), K2)
+	return t.ChallengeScalar([]byte("c"))
+}
+
+// hashRing computes Hp(P) for every key in the ring
+func hashRing(ring []ristretto.Point) []ristretto.Point {
+	hashes := make([]ristretto.Point, len(ring))
+	for i := range ring {
+		hashes[i].Derive(ring[i].Bytes())
+	}
+	return hashes
+}
+
+// powers returns x^0 .. x^(n-1)
+func powers(x ristretto.Scalar, n int) []ristretto.Scalar {
+	res := make([]ristretto.Scalar, n)
+	res[0].SetOne()
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+	return res
+}
+
+// ringExponent returns m such that n = 2^m
+func ringExponent(n int) (int, error) {
+	if n < 2 || n > MaxRingSize || n&(n-1) != 0 {
+		return 0, fmt.Errorf("ring size must be a power of two between 2 and %d", MaxRingSize)
+	}
+
+	m := 0
+	for (1 << uint(m)) < n {
+		m++
+	}
+	return m, nil
+}
+
+// Encode writes the signature, including the key image
+func (sig *Signature) Encode(w io.Writer) error {
+
+	m := len(sig.f)
+	if m == 0 || m > maxM || len(sig.X) != m || len(sig.Y) != m || len(sig.V) != m {
+		return errors.New("signature is malformed")
+	}
+
+	err := binary.Write(w, binary.BigEndian, uint8(m))
+	if err != nil {
+		return err
+	}
+
+	points := []ristretto.Point{sig.KeyImage, sig.A, sig.B, sig.C, sig.D}
+	points = append(points, sig.X...)
+	points = append(points, sig.Y...)
+	points = append(points, sig.V...)
+	for i := range points {
+		err = binary.Write(w, binary.BigEndian, points[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	scalars := append([]ristretto.Scalar{}, sig.f...)
+	scalars = append(scalars, sig.zA, sig.zC, sig.c, sig.s1, sig.s2)
+	for i := range scalars {
+		err = binary.Write(w, binary.BigEndian, scalars[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a signature written with Encode
+func (sig *Signature) Decode(r io.Reader) error {
+
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	var m uint8
+	err := binary.Read(r, binary.BigEndian, &m)
+	if err != nil {
+		return err
+	}
+	if m == 0 || m > maxM {
+		return fmt.Errorf("ring exponent %d is out of range", m)
+	}
+
+	sig.X = make([]ristretto.Point, m)
+	sig.Y = make([]ristretto.Point, m)
+	sig.V = make([]ristretto.Point, m)
+	sig.f = make([]ristretto.Scalar, m)
+
+	points := []*ristretto.Point{&sig.KeyImage, &sig.A, &sig.B, &sig.C, &sig.D}
+	for _, vec := range [][]ristretto.Point{sig.X, sig.Y, sig.V} {
+		for i := range vec {
+			points = append(points, &vec[i])
+		}
+	}
+	for _, p := range points {
+		err = readerToPoint(r, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	scalars := []*ristretto.Scalar{}
+	for i := range sig.f {
+		scalars = append(scalars, &sig.f[i])
+	}
+	scalars = append(scalars, &sig.zA, &sig.zC, &sig.c, &sig.s1, &sig.s2)
+	for _, s := range scalars {
+		err = readerToScalar(r, s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both signatures are the same
+func (sig *Signature) Equals(other *Signature) bool {
+	if len(sig.f) != len(other.f) || len(sig.X) != len(other.X) ||
+		len(sig.Y) != len(other.Y) || len(sig.V) != len(other.V) {
+		return false
+	}
+
+	points := []ristretto.Point{sig.KeyImage, sig.A, sig.B, sig.C, sig.D}
+	otherPoints := []ristretto.Point{other.KeyImage, other.A, other.B, other.C, other.D}
+	points = append(append(append(points, sig.X...), sig.Y...), sig.V...)
+	otherPoints = append(append(append(otherPoints, other.X...), other.Y...), other.V...)
+	for i := range points {
+		if !points[i].Equals(&otherPoints[i]) {
+			return false
+		}
+	}
+
+	scalars := append([]ristretto.Scalar{}, sig.f...)
+	scalars = append(scalars, sig.zA, sig.zC, sig.c, sig.s1, sig.s2)
+	otherScalars := append([]ristretto.Scalar{}, other.f...)
+	otherScalars = append(otherScalars, other.zA, other.zC, other.c, other.s1, other.s2)
+	for i := range scalars {
+		if !scalars[i].Equals(&otherScalars[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
+
+func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	return nil
+}
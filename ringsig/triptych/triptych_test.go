@@ -0,0 +1,236 @@
+package triptych
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/mlsag"
+)
+
+func TestSignVerify(t *testing.T) {
+	msg := []byte("hello world")
+
+	for _, n := range []int{2, 4, 32, 128} {
+		ring, privKey, index := generateRing(n)
+
+		sig, err := Sign(msg, ring, index, privKey)
+		require.Nil(t, err)
+
+		ok, err := Verify(msg, ring, sig)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestKeyImage(t *testing.T) {
+	ring, privKey, index := generateRing(8)
+
+	sig, err := Sign([]byte("hello world"), ring, index, privKey)
+	require.Nil(t, err)
+
+	// The key image has the same format as the MLSAG key images
+	expected := mlsag.CalculateKeyImage(privKey, ring[index])
+	assert.True(t, expected.Equals(&sig.KeyImage))
+}
+
+func TestLink(t *testing.T) {
+	ring, privKey, index := generateRing(16)
+
+	a, err := Sign([]byte("first"), ring, index, privKey)
+	require.Nil(t, err)
+
+	// The same key in a different ring and position links
+	otherRing, _, _ := generateRing(16)
+	otherRing[3] = ring[index]
+	b, err := Sign([]byte("second"), otherRing, 3, privKey)
+	require.Nil(t, err)
+	assert.True(t, Link(a, b))
+
+	otherKey := (index + 1) % len(ring)
+	ring[otherKey], privKey = randKey()
+	c, err := Sign([]byte("third"), ring, otherKey, privKey)
+	require.Nil(t, err)
+	assert.False(t, Link(a, c))
+}
+
+func TestBadSig(t *testing.T) {
+
+	tamper := map[string]func(msg *[]byte, ring []ristretto.Point, sig *Signature){
+		"message": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			*msg = []byte("something random")
+		},
+		"key image": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.KeyImage.Rand()
+		},
+		"identity key image": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.KeyImage.SetZero()
+		},
+		"ring member": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			ring[0].Rand()
+		},
+		"ring order": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			ring[0], ring[1] = ring[1], ring[0]
+		},
+		"bit commitment": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.B.Rand()
+		},
+		"collapsed ring": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.Y[0].Rand()
+		},
+		"response": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.f[0].Rand()
+		},
+		"sigma response": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.s1.Rand()
+		},
+		"missing term": func(msg *[]byte, ring []ristretto.Point, sig *Signature) {
+			sig.V = sig.V[1:]
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			msg := []byte("hello world")
+			ring, privKey, index := generateRing(8)
+
+			sig, err := Sign(msg, ring, index, privKey)
+			require.Nil(t, err)
+
+			fn(&msg, ring, sig)
+
+			ok, err := Verify(msg, ring, sig)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestWrongRingSize(t *testing.T) {
+	msg := []byte("hello world")
+	ring, privKey, index := generateRing(8)
+
+	sig, err := Sign(msg, ring, index, privKey)
+	require.Nil(t, err)
+
+	ok, err := Verify(msg, ring[:4], sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	for _, n := range []int{0, 1, 3, 12, MaxRingSize * 2} {
+		ring := make([]ristretto.Point, n)
+		_, err := Sign(msg, ring, 0, privKey)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestSignBadKey(t *testing.T) {
+	msg := []byte("hello world")
+	ring, privKey, index := generateRing(4)
+
+	// private key does not belong to the signer index
+	_, err := Sign(msg, ring, (index+1)%len(ring), privKey)
+	assert.NotNil(t, err)
+
+	_, err = Sign(msg, ring, len(ring), privKey)
+	assert.NotNil(t, err)
+
+	var zero ristretto.Scalar
+	zero.SetZero()
+	_, err = Sign(msg, ring, index, zero)
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	msg := []byte("hello world")
+	ring, privKey, index := generateRing(128)
+
+	sig, err := Sign(msg, ring, index, privKey)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf)
+	require.Nil(t, err)
+
+	// 1 byte for m, 5 + 3m points and m + 5 scalars
+	assert.Equal(t, 1+32*(5+3*7)+32*(7+5), buf.Len())
+
+	decodedSig := &Signature{}
+	err = decodedSig.Decode(buf)
+	require.Nil(t, err)
+	assert.Equal(t, 0, buf.Len())
+	assert.True(t, sig.Equals(decodedSig))
+
+	ok, err := Verify(msg, ring, decodedSig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	ring, privKey, index := generateRing(4)
+
+	sig, err := Sign([]byte("hello world"), ring, index, privKey)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf)
+	require.Nil(t, err)
+	encoded := buf.Bytes()
+
+	// ring exponent out of range
+	for _, m := range []byte{0, maxM + 1} {
+		malformed := append([]byte{m}, encoded[1:]...)
+		err = (&Signature{}).Decode(bytes.NewReader(malformed))
+		assert.NotNil(t, err)
+	}
+
+	// truncated
+	err = (&Signature{}).Decode(bytes.NewReader(encoded[:len(encoded)-1]))
+	assert.NotNil(t, err)
+}
+
+func BenchmarkSign(b *testing.B) {
+	msg := []byte("hello world")
+	ring, privKey, index := generateRing(128)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sign(msg, ring, index, privKey)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	msg := []byte("hello world")
+	ring, privKey, index := generateRing(128)
+	sig, _ := Sign(msg, ring, index, privKey)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(msg, ring, sig)
+	}
+}
+
+func randKey() (ristretto.Point, ristretto.Scalar) {
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+	return pubKey, privKey
+}
+
+// generateRing returns a random ring of size n, with the signers
+// private key and index
+func generateRing(n int) ([]ristretto.Point, ristretto.Scalar, int) {
+	ring := make([]ristretto.Point, n)
+	for i := range ring {
+		ring[i].Rand()
+	}
+
+	index := n / 3
+	pubKey, privKey := randKey()
+	ring[index] = pubKey
+	return ring, privKey, index
+}
@@ -0,0 +1,63 @@
+package schnorr
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// VerifyBatch verifies many signatures of the same domain at once.
+// Every equation s_i * G = R_i + e_i * P_i is weighted by a random z_i
+// and the weighted equations are summed, so that the whole batch costs
+// a single base point multiplication. A failing batch does not tell
+// which signature is invalid, in which case they can be verified one by one
+func VerifyBatch(domain []byte, pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) (bool, error) {
+
+	if len(pubKeys) != len(msgs) || len(pubKeys) != len(sigs) {
+		return false, errors.New("number of public keys, messages and signatures do not match")
+	}
+	if len(sigs) == 0 {
+		return false, errors.New("batch cannot be empty")
+	}
+
+	// sum(z_i * s_i) * G = sum(z_i * R_i) + sum(z_i * e_i * P_i)
+	var sumS ristretto.Scalar
+	sumS.SetZero()
+
+	var rhs ristretto.Point
+	rhs.SetZero()
+
+	for i := range sigs {
+		if sigs[i] == nil {
+			return false, errors.New("signature is nil")
+		}
+		if isIdentity(pubKeys[i]) {
+			return false, errors.New("public key cannot be the identity")
+		}
+
+		var z ristretto.Scalar
+		z.Rand()
+
+		e := Challenge(domain, sigs[i].R, pubKeys[i], msgs[i])
+
+		sumS.MulAdd(&z, &sigs[i].S, &sumS)
+
+		var zR, zeP ristretto.Point
+		zR.PublicScalarMult(&sigs[i].R, &z)
+
+		var ze ristretto.Scalar
+		ze.Mul(&z, &e)
+		zeP.PublicScalarMult(&pubKeys[i], &ze)
+
+		rhs.Add(&rhs, &zR)
+		rhs.Add(&rhs, &zeP)
+	}
+
+	var lhs ristretto.Point
+	lhs.PublicScalarMultBase(&sumS)
+
+	if !lhs.Equals(&rhs) {
+		return false, errors.New("batch contains an invalid signature")
+	}
+	return true, nil
+}
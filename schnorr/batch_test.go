@@ -0,0 +1,93 @@
+package schnorr
+
+import (
+	"fmt"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	pubKeys, msgs, sigs := generateBatch(t, 16)
+
+	ok, err := VerifyBatch(DefaultDomain, pubKeys, msgs, sigs)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyBatchInvalid(t *testing.T) {
+
+	tamper := map[string]func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature){
+		"message": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			msgs[3] = []byte("something random")
+		},
+		"public key": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			pubKeys[5].Rand()
+		},
+		"swapped signatures": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			sigs[0], sigs[1] = sigs[1], sigs[0]
+		},
+		"response": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			sigs[7].S.Rand()
+		},
+		"nil signature": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			sigs[2] = nil
+		},
+		"identity public key": func(pubKeys []ristretto.Point, msgs [][]byte, sigs []*Signature) {
+			pubKeys[4].SetZero()
+		},
+	}
+
+	for name, fn := range tamper {
+		t.Run(name, func(t *testing.T) {
+			pubKeys, msgs, sigs := generateBatch(t, 8)
+			fn(pubKeys, msgs, sigs)
+
+			ok, err := VerifyBatch(DefaultDomain, pubKeys, msgs, sigs)
+			assert.NotNil(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestVerifyBatchMalformed(t *testing.T) {
+	pubKeys, msgs, sigs := generateBatch(t, 4)
+
+	_, err := VerifyBatch(DefaultDomain, pubKeys[1:], msgs, sigs)
+	assert.NotNil(t, err)
+
+	_, err = VerifyBatch(DefaultDomain, nil, nil, nil)
+	assert.NotNil(t, err)
+
+	_, err = VerifyBatch([]byte("vosbor.rpc.auth"), pubKeys, msgs, sigs)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	pubKeys, msgs, sigs := generateBatch(b, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyBatch(DefaultDomain, pubKeys, msgs, sigs)
+	}
+}
+
+func generateBatch(t require.TestingT, n int) ([]ristretto.Point, [][]byte, []*Signature) {
+	pubKeys := make([]ristretto.Point, n)
+	msgs := make([][]byte, n)
+	sigs := make([]*Signature, n)
+
+	for i := 0; i < n; i++ {
+		var privKey ristretto.Scalar
+		privKey.Rand()
+		pubKeys[i].ScalarMultBase(&privKey)
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+
+		sig, err := Sign(privKey, msgs[i])
+		require.Nil(t, err)
+		sigs[i] = sig
+	}
+	return pubKeys, msgs, sigs
+}
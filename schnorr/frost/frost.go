@@ -0,0 +1,124 @@
+// Package frost implements FROST threshold Schnorr signatures over
+// ristretto, following the FROST(ristretto255, SHA-512) ciphersuite of
+// RFC 9591.
+//
+// A group key is split into n shares, any t of which can sign. Signing
+// takes two rounds: every participant publishes a pair of nonce
+// commitments, and once the commitments of all signers are known each of
+// them creates a signature share. The coordinator verifies the shares,
+// naming any participant that misbehaved, and aggregates them into a
+// signature (R, z) that verifies under the group key with z * G = R + c * PK.
+//
+// Keys are the same scalars and points used by the ring signatures.
+// Signatures use the challenge of the RFC rather than the one of the
+// schnorr package, but share its 64 byte encoding.
+package frost
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/schnorr"
+)
+
+// contextString is the ciphersuite identifier of RFC 9591
+var contextString = []byte("FROST-RISTRETTO255-SHA512-v1")
+
+// Verify verifies a signature under the group key, z * G = R + c * PK
+func Verify(groupKey ristretto.Point, msg []byte, sig *schnorr.Signature) (bool, error) {
+
+	if sig == nil {
+		return false, errors.New("signature is nil")
+	}
+	if isIdentity(groupKey) {
+		return false, errors.New("group key cannot be the identity")
+	}
+
+	c := challenge(sig.R, groupKey, msg)
+
+	var lhs, rhs, cPK ristretto.Point
+	lhs.ScalarMultBase(&sig.S)
+	cPK.PublicScalarMult(&groupKey, &c)
+	rhs.Add(&sig.R, &cPK)
+
+	if !lhs.Equals(&rhs) {
+		return false, errors.New("signature is not valid")
+	}
+	return true, nil
+}
+
+// challenge returns H2(R || PK || msg)
+func challenge(R, groupKey ristretto.Point, msg []byte) ristretto.Scalar {
+	return hashToScalar("chal", R.Bytes(), groupKey.Bytes(), msg)
+}
+
+// hashToScalar implements H1, H2 and H3 of the ciphersuite,
+// SHA-512(contextString || tag || m) reduced modulo the group order
+func hashToScalar(tag string, data ...[]byte) ristretto.Scalar {
+	var digest [64]byte
+	copy(digest[:], hash(tag, data...))
+
+	var s ristretto.Scalar
+	s.SetReduced(&digest)
+	return s
+}
+
+// hash implements H4 and H5 of the ciphersuite, SHA-512(contextString || tag || m)
+func hash(tag string, data ...[]byte) []byte {
+	h := sha512.New()
+	h.Write(contextString)
+	h.Write([]byte(tag))
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// identifierToScalar maps a participant identifier to its scalar
+func identifierToScalar(id uint32) ristretto.Scalar {
+	var s ristretto.Scalar
+	s.SetBigInt(new(big.Int).SetUint64(uint64(id)))
+	return s
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient of id
+// when interpolating at zero over the identifiers ids
+func lagrangeCoefficient(id uint32, ids []uint32) (ristretto.Scalar, error) {
+
+	var num, den ristretto.Scalar
+	num.SetOne()
+	den.SetOne()
+
+	found := false
+	x := identifierToScalar(id)
+	for _, other := range ids {
+		if other == id {
+			found = true
+			continue
+		}
+		xj := identifierToScalar(other)
+
+		var diff ristretto.Scalar
+		diff.Sub(&xj, &x)
+		num.Mul(&num, &xj)
+		den.Mul(&den, &diff)
+	}
+
+	if !found {
+		return ristretto.Scalar{}, errors.New("identifier is not part of the signers")
+	}
+
+	var lambda ristretto.Scalar
+	lambda.Inverse(&den)
+	lambda.Mul(&lambda, &num)
+	return lambda, nil
+}
+
+func isIdentity(p ristretto.Point) bool {
+	var buf [32]byte
+	p.BytesInto(&buf)
+	return bytes.Equal(buf[:], make([]byte, 32))
+}
@@ -0,0 +1,147 @@
+package frost
+
+import (
+	"encoding/hex"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of the FROST(ristretto255, SHA-512) ciphersuite,
+// RFC 9591 appendix E.4. The dealer splits the key between 3 participants
+// with a threshold of 2, and participants 1 and 3 sign
+var (
+	vectorGroupSecret = "1b25a55e463cfd15cf14a5d3acc3d15053f08da49c8afcf3ab265f2ebc4f970b"
+	vectorGroupKey    = "e2a62f39eede11269e3bd5a7d97554f5ca384f9f6d3dd9c3c0d05083c7254f57"
+	vectorCoefficient = "410f8b744b19325891d73736923525a4f596c805d060dfb9c98009d34e3fec02"
+	vectorMsg         = "74657374"
+	vectorShares      = []string{
+		"5c3430d391552f6e60ecdc093ff9f6f4488756aa6cebdbad75a768010b8f830e",
+		"b06fc5eac20b4f6e1b271d9df2343d843e1e1fb03c4cbb673f2872d459ce6f01",
+		"f17e505f0e2581c6acfe54d3846a622834b5e7b50cad9a2109a97ba7a80d5c04",
+	}
+	vectorSigners = []struct {
+		id                          uint32
+		hidingRand, bindingRand     string
+		hidingNonce, bindingNonce   string
+		hidingCommit, bindingCommit string
+		bindingFactor, sigShare     string
+	}{
+		{
+			id:            1,
+			hidingRand:    "f595a133b4d95c6e1f79887220c8b275ce6277e7f68a6640e1e7140f9be2fb5c",
+			bindingRand:   "34dd1001360e3513cb37bebfabe7be4a32c5bb91ba19fbd4360d039111f0fbdc",
+			hidingNonce:   "214f2cabb86ed71427ea7ad4283b0fae26b6746c801ce824b83ceb2b99278c03",
+			bindingNonce:  "c9b8f5e16770d15603f744f8694c44e335e8faef00dad182b8d7a34a62552f0c",
+			hidingCommit:  "965def4d0958398391fc06d8c2d72932608b1e6255226de4fb8d972dac15fd57",
+			bindingCommit: "ec5170920660820007ae9e1d363936659ef622f99879898db86e5bf1d5bf2a14",
+			bindingFactor: "8967fd70fa06a58e5912603317fa94c77626395a695a0e4e4efc4476662eba0c",
+			sigShare:      "9285f875923ce7e0c491a592e9ea1865ec1b823ead4854b48c8a46287749ee09",
+		},
+		{
+			id:            3,
+			hidingRand:    "daa0cf42a32617786d390e0c7edfbf2efbd428037069357b5173ae61d6dd5d5e",
+			bindingRand:   "b4387e72b2e4108ce4168931cc2c7fcce5f345a5297368952c18b5fc8473f050",
+			hidingNonce:   "3f7927872b0f9051dd98dd73eb2b91494173bbe0feb65a3e7e58d3e2318fa40f",
+			bindingNonce:  "ffd79445fb8030f0a3ddd3861aa4b42b618759282bfe24f1f9304c7009728305",
+			hidingCommit:  "480e06e3de182bf83489c45d7441879932fd7b434a26af41455756264fbd5d6e",
+			bindingCommit: "3064746dfd3c1862ef58fc68c706da287dd925066865ceacc816b3a28c7b363b",
+			bindingFactor: "f2c1bb7c33a10511158c2f1766a4a5fadf9f86f2a92692ed333128277cc31006",
+			sigShare:      "7cb211fe0e3d59d25db6e36b3fb32344794139602a7b24f1ae0dc4e26ad7b908",
+		},
+	}
+	vectorSig = "fc45655fbc66bbffad654ea4ce5fdae253a49a64ace25d9adb62010dd9fb25552164141787162e5b4cab915b4aa45d94655dbb9ed7c378a53b980a0be220a802"
+)
+
+func TestVectors(t *testing.T) {
+	msg := hexToBytes(t, vectorMsg)
+
+	shares, vss, err := splitSecret(hexToScalar(t, vectorGroupSecret), []ristretto.Scalar{hexToScalar(t, vectorCoefficient)}, 3)
+	require.Nil(t, err)
+	assert.Equal(t, vectorGroupKey, hex.EncodeToString(vss[0].Bytes()))
+	for i := range shares {
+		assert.Equal(t, vectorShares[i], hex.EncodeToString(shares[i].Secret.Bytes()))
+		assert.Nil(t, VerifyShare(shares[i], vss))
+	}
+
+	nonces := make([]*SigningNonces, len(vectorSigners))
+	commitments := make([]SigningCommitment, len(vectorSigners))
+	for i, v := range vectorSigners {
+		nonces[i], commitments[i] = commitWithRandomness(shares[v.id-1], hexToArray(t, v.hidingRand), hexToArray(t, v.bindingRand))
+		assert.Equal(t, v.hidingNonce, hex.EncodeToString(nonces[i].hiding.Bytes()))
+		assert.Equal(t, v.bindingNonce, hex.EncodeToString(nonces[i].binding.Bytes()))
+		assert.Equal(t, v.hidingCommit, hex.EncodeToString(commitments[i].Hiding.Bytes()))
+		assert.Equal(t, v.bindingCommit, hex.EncodeToString(commitments[i].Binding.Bytes()))
+	}
+
+	rho, _, err := bindingFactors(vss[0], commitments, msg)
+	require.Nil(t, err)
+
+	pubKeyShares := make(map[uint32]ristretto.Point)
+	sigShares := make([]SignatureShare, len(vectorSigners))
+	for i, v := range vectorSigners {
+		r := rho[v.id]
+		assert.Equal(t, v.bindingFactor, hex.EncodeToString(r.Bytes()))
+
+		sigShares[i], err = Sign(shares[v.id-1], nonces[i], commitments, msg)
+		require.Nil(t, err)
+		assert.Equal(t, v.sigShare, hex.EncodeToString(sigShares[i].Z.Bytes()))
+
+		pubKeyShares[v.id] = shares[v.id-1].PubKey
+	}
+
+	sig, err := Aggregate(vss[0], pubKeyShares, commitments, msg, sigShares)
+	require.Nil(t, err)
+	b := sig.Bytes()
+	assert.Equal(t, vectorSig, hex.EncodeToString(b[:]))
+
+	ok, err := Verify(vss[0], msg, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestLagrangeCoefficient(t *testing.T) {
+	// interpolating f(x) = s + a * x at zero from two points recovers s
+	var s, a ristretto.Scalar
+	s.Rand()
+	a.Rand()
+
+	ids := []uint32{2, 7}
+	var sum ristretto.Scalar
+	sum.SetZero()
+	for _, id := range ids {
+		x := identifierToScalar(id)
+		var y ristretto.Scalar
+		y.MulAdd(&a, &x, &s)
+
+		lambda, err := lagrangeCoefficient(id, ids)
+		require.Nil(t, err)
+		sum.MulAdd(&lambda, &y, &sum)
+	}
+	assert.True(t, sum.Equals(&s))
+
+	_, err := lagrangeCoefficient(3, ids)
+	assert.NotNil(t, err)
+}
+
+func hexToBytes(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.Nil(t, err)
+	return b
+}
+
+func hexToArray(t *testing.T, s string) [32]byte {
+	var buf [32]byte
+	copy(buf[:], hexToBytes(t, s))
+	return buf
+}
+
+func hexToScalar(t *testing.T, s string) ristretto.Scalar {
+	buf := hexToArray(t, s)
+
+	var x ristretto.Scalar
+	x.SetBytes(&buf)
+	return x
+}
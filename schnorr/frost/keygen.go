@@ -0,0 +1,115 @@
+package frost
+
+import (
+	"errors"
+	"fmt"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// KeyShare is the signing share of a participant
+type KeyShare struct {
+	ID       uint32
+	Secret   ristretto.Scalar
+	PubKey   ristretto.Point
+	GroupKey ristretto.Point
+}
+
+// VSSCommitment commits to the coefficients of the polynomial used to
+// split the group key, C_j = a_j * G, with C_0 the group key itself
+type VSSCommitment []ristretto.Point
+
+// TrustedDealerKeygen splits secret into n shares of which any t can sign.
+// Participants are identified by 1..n. The commitment lets every
+// participant verify its share with VerifyShare
+func TrustedDealerKeygen(secret ristretto.Scalar, n, t int) ([]KeyShare, VSSCommitment, error) {
+
+	if t < 1 || t > n {
+		return nil, nil, fmt.Errorf("threshold must be between 1 and %d, got %d", n, t)
+	}
+
+	coefs := make([]ristretto.Scalar, t-1)
+	for i := range coefs {
+		coefs[i].Rand()
+	}
+	return splitSecret(secret, coefs, n)
+}
+
+// splitSecret evaluates f(x) = secret + sum(coefs[j-1] * x^j) at 1..n
+func splitSecret(secret ristretto.Scalar, coefs []ristretto.Scalar, n int) ([]KeyShare, VSSCommitment, error) {
+
+	if secret.IsNonZeroI() == 0 {
+		return nil, nil, errors.New("secret cannot be zero")
+	}
+	if n < 1 || uint64(n) > uint64(^uint32(0)) {
+		return nil, nil, fmt.Errorf("invalid number of participants %d", n)
+	}
+
+	poly := append([]ristretto.Scalar{secret}, coefs...)
+
+	vss := make(VSSCommitment, len(poly))
+	for j := range poly {
+		vss[j].ScalarMultBase(&poly[j])
+	}
+
+	shares := make([]KeyShare, n)
+	for i := range shares {
+		id := uint32(i + 1)
+		x := identifierToScalar(id)
+
+		// Horner's rule
+		var y ristretto.Scalar
+		y.SetZero()
+		for j := len(poly) - 1; j >= 0; j-- {
+			y.MulAdd(&y, &x, &poly[j])
+		}
+
+		shares[i] = KeyShare{ID: id, Secret: y, GroupKey: vss[0]}
+		shares[i].PubKey.ScalarMultBase(&y)
+	}
+
+	return shares, vss, nil
+}
+
+// VerifyShare checks a share against the commitment of the dealer,
+// s_i * G = sum(C_j * i^j)
+func VerifyShare(share KeyShare, vss VSSCommitment) error {
+
+	if len(vss) == 0 {
+		return errors.New("commitment is empty")
+	}
+	if share.ID == 0 {
+		return errors.New("identifier cannot be zero")
+	}
+
+	expected := vss.PubKeyShare(share.ID)
+
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&share.Secret)
+
+	if !pubKey.Equals(&expected) || !share.PubKey.Equals(&expected) {
+		return fmt.Errorf("share of participant %d does not match the commitment", share.ID)
+	}
+	if !share.GroupKey.Equals(&vss[0]) {
+		return errors.New("group key does not match the commitment")
+	}
+	return nil
+}
+
+// PubKeyShare returns the public key share of a participant
+func (vss VSSCommitment) PubKeyShare(id uint32) ristretto.Point {
+	x := identifierToScalar(id)
+
+	var xj ristretto.Scalar
+	xj.SetOne()
+
+	var pubKey ristretto.Point
+	pubKey.SetZero()
+	for j := range vss {
+		var term ristretto.Point
+		term.PublicScalarMult(&vss[j], &xj)
+		pubKey.Add(&pubKey, &term)
+		xj.Mul(&xj, &x)
+	}
+	return pubKey
+}
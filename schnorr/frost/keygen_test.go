@@ -0,0 +1,95 @@
+package frost
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedDealerKeygen(t *testing.T) {
+	var secret ristretto.Scalar
+	secret.Rand()
+
+	shares, vss, err := TrustedDealerKeygen(secret, 5, 3)
+	require.Nil(t, err)
+	assert.Equal(t, 5, len(shares))
+	assert.Equal(t, 3, len(vss))
+
+	var groupKey ristretto.Point
+	groupKey.ScalarMultBase(&secret)
+	assert.True(t, vss[0].Equals(&groupKey))
+
+	for i := range shares {
+		assert.Equal(t, uint32(i+1), shares[i].ID)
+		assert.True(t, shares[i].GroupKey.Equals(&groupKey))
+		assert.Nil(t, VerifyShare(shares[i], vss))
+
+		pubKey := vss.PubKeyShare(shares[i].ID)
+		assert.True(t, shares[i].PubKey.Equals(&pubKey))
+	}
+
+	// any 3 shares recover the secret, 2 do not
+	recovered := interpolate(t, shares[1:4])
+	assert.True(t, recovered.Equals(&secret))
+	recovered = interpolate(t, shares[:2])
+	assert.False(t, recovered.Equals(&secret))
+}
+
+func TestVerifyShareInvalid(t *testing.T) {
+	var secret ristretto.Scalar
+	secret.Rand()
+
+	shares, vss, err := TrustedDealerKeygen(secret, 4, 2)
+	require.Nil(t, err)
+
+	tampered := shares[1]
+	tampered.Secret.Rand()
+	assert.NotNil(t, VerifyShare(tampered, vss))
+
+	tampered = shares[1]
+	tampered.ID = 3
+	assert.NotNil(t, VerifyShare(tampered, vss))
+
+	tampered = shares[1]
+	tampered.GroupKey.Rand()
+	assert.NotNil(t, VerifyShare(tampered, vss))
+
+	tampered = shares[1]
+	tampered.ID = 0
+	assert.NotNil(t, VerifyShare(tampered, vss))
+
+	assert.NotNil(t, VerifyShare(shares[1], nil))
+}
+
+func TestTrustedDealerKeygenInvalid(t *testing.T) {
+	var secret ristretto.Scalar
+	secret.Rand()
+
+	_, _, err := TrustedDealerKeygen(secret, 3, 4)
+	assert.NotNil(t, err)
+
+	_, _, err = TrustedDealerKeygen(secret, 3, 0)
+	assert.NotNil(t, err)
+
+	secret.SetZero()
+	_, _, err = TrustedDealerKeygen(secret, 3, 2)
+	assert.NotNil(t, err)
+}
+
+func interpolate(t *testing.T, shares []KeyShare) ristretto.Scalar {
+	ids := make([]uint32, len(shares))
+	for i := range shares {
+		ids[i] = shares[i].ID
+	}
+
+	var secret ristretto.Scalar
+	secret.SetZero()
+	for i := range shares {
+		lambda, err := lagrangeCoefficient(shares[i].ID, ids)
+		require.Nil(t, err)
+		secret.MulAdd(&lambda, &shares[i].Secret, &secret)
+	}
+	return secret
+}
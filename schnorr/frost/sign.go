@@ -0,0 +1,306 @@
+package frost
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/schnorr"
+)
+
+// SigningNonces are the secret nonces of a participant for one signature.
+// They are cleared once they have been used
+type SigningNonces struct {
+	hiding, binding ristretto.Scalar
+	used            bool
+}
+
+// SigningCommitment is the public commitment to the nonces of a participant,
+// sent to the coordinator in the first round
+type SigningCommitment struct {
+	ID      uint32
+	Hiding  ristretto.Point
+	Binding ristretto.Point
+}
+
+// SignatureShare is the share of a participant, sent to the coordinator
+// in the second round
+type SignatureShare struct {
+	ID uint32
+	Z  ristretto.Scalar
+}
+
+// InvalidShareError identifies the participant whose signature share
+// does not verify
+type InvalidShareError struct {
+	ID uint32
+}
+
+func (e *InvalidShareError) Error() string {
+	return fmt.Sprintf("signature share of participant %d is not valid", e.ID)
+}
+
+// Commit draws the nonces of the first round
+func Commit(share KeyShare) (*SigningNonces, SigningCommitment, error) {
+
+	var hidingRand, bindingRand [32]byte
+	if _, err := rand.Read(hidingRand[:]); err != nil {
+		return nil, SigningCommitment{}, err
+	}
+	if _, err := rand.Read(bindingRand[:]); err != nil {
+		return nil, SigningCommitment{}, err
+	}
+
+	nonces, comm := commitWithRandomness(share, hidingRand, bindingRand)
+	return nonces, comm, nil
+}
+
+func commitWithRandomness(share KeyShare, hidingRand, bindingRand [32]byte) (*SigningNonces, SigningCommitment) {
+	nonces := &SigningNonces{
+		hiding:  generateNonce(share.Secret, hidingRand),
+		binding: generateNonce(share.Secret, bindingRand),
+	}
+
+	comm := SigningCommitment{ID: share.ID}
+	comm.Hiding.ScalarMultBase(&nonces.hiding)
+	comm.Binding.ScalarMultBase(&nonces.binding)
+	return nonces, comm
+}
+
+// generateNonce returns H3(random || secret)
+func generateNonce(secret ristretto.Scalar, random [32]byte) ristretto.Scalar {
+	return hashToScalar("nonce", random[:], secret.Bytes())
+}
+
+// Sign creates the signature share of the second round, given the
+// commitments of every signer
+func Sign(share KeyShare, nonces *SigningNonces, commitments []SigningCommitment, msg []byte) (SignatureShare, error) {
+
+	if nonces == nil || nonces.used {
+		return SignatureShare{}, errors.New("signing nonces have already been used")
+	}
+
+	commitments, err := sortCommitments(commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	comm, err := findCommitment(share.ID, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	var hiding, binding ristretto.Point
+	hiding.ScalarMultBase(&nonces.hiding)
+	binding.ScalarMultBase(&nonces.binding)
+	if !hiding.Equals(&comm.Hiding) || !binding.Equals(&comm.Binding) {
+		return SignatureShare{}, errors.New("signing nonces do not match the commitment of the participant")
+	}
+
+	rho, R, err := bindingFactors(share.GroupKey, commitments, msg)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	lambda, err := lagrangeCoefficient(share.ID, identifiers(commitments))
+	if err != nil {
+		return SignatureShare{}, err
+	}
+	c := challenge(R, share.GroupKey, msg)
+
+	// z_i = d_i + e_i * rho_i + lambda_i * s_i * c
+	var lc ristretto.Scalar
+	lc.Mul(&lambda, &c)
+	r := rho[share.ID]
+
+	z := SignatureShare{ID: share.ID}
+	z.Z.MulAdd(&nonces.binding, &r, &nonces.hiding)
+	z.Z.MulAdd(&lc, &share.Secret, &z.Z)
+
+	nonces.hiding.SetZero()
+	nonces.binding.SetZero()
+	nonces.used = true
+
+	return z, nil
+}
+
+// VerifySignatureShare checks the share of a participant against its
+// commitment and public key share
+func VerifySignatureShare(sigShare SignatureShare, pubKeyShare, groupKey ristretto.Point, commitments []SigningCommitment, msg []byte) error {
+
+	commitments, err := sortCommitments(commitments)
+	if err != nil {
+		return err
+	}
+
+	rho, R, err := bindingFactors(groupKey, commitments, msg)
+	if err != nil {
+		return err
+	}
+
+	return verifyShare(sigShare, pubKeyShare, groupKey, commitments, rho, R, msg)
+}
+
+func verifyShare(sigShare SignatureShare, pubKeyShare, groupKey ristretto.Point, commitments []SigningCommitment, rho map[uint32]ristretto.Scalar, R ristretto.Point, msg []byte) error {
+
+	comm, err := findCommitment(sigShare.ID, commitments)
+	if err != nil {
+		return err
+	}
+
+	lambda, err := lagrangeCoefficient(sigShare.ID, identifiers(commitments))
+	if err != nil {
+		return err
+	}
+	c := challenge(R, groupKey, msg)
+
+	var lc ristretto.Scalar
+	lc.Mul(&lambda, &c)
+	r := rho[sigShare.ID]
+
+	// z_i * G = D_i + rho_i * E_i + lambda_i * c * PK_i
+	var lhs, rhs, rE, lcPK ristretto.Point
+	lhs.ScalarMultBase(&sigShare.Z)
+	rE.PublicScalarMult(&comm.Binding, &r)
+	lcPK.PublicScalarMult(&pubKeyShare, &lc)
+	rhs.Add(&comm.Hiding, &rE)
+	rhs.Add(&rhs, &lcPK)
+
+	if !lhs.Equals(&rhs) {
+		return &InvalidShareError{ID: sigShare.ID}
+	}
+	return nil
+}
+
+// Aggregate verifies the signature shares and combines them into a
+// signature under the group key. pubKeyShares maps the identifier of
+// every signer to its public key share. If a share does not verify,
+// an *InvalidShareError names the participant that produced it
+func Aggregate(groupKey ristretto.Point, pubKeyShares map[uint32]ristretto.Point, commitments []SigningCommitment, msg []byte, sigShares []SignatureShare) (*schnorr.Signature, error) {
+
+	commitments, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigShares) != len(commitments) {
+		return nil, fmt.Errorf("expected %d signature shares, got %d", len(commitments), len(sigShares))
+	}
+
+	rho, R, err := bindingFactors(groupKey, commitments, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint32]bool, len(sigShares))
+	sig := &schnorr.Signature{R: R}
+	sig.S.SetZero()
+	for _, sigShare := range sigShares {
+		if seen[sigShare.ID] {
+			return nil, fmt.Errorf("duplicate signature share of participant %d", sigShare.ID)
+		}
+		seen[sigShare.ID] = true
+
+		pubKeyShare, ok := pubKeyShares[sigShare.ID]
+		if !ok {
+			return nil, fmt.Errorf("no public key share for participant %d", sigShare.ID)
+		}
+		if err := verifyShare(sigShare, pubKeyShare, groupKey, commitments, rho, R, msg); err != nil {
+			return nil, err
+		}
+		sig.S.Add(&sig.S, &sigShare.Z)
+	}
+
+	// valid shares from fewer than t signers still combine into an
+	// invalid signature
+	if _, err := Verify(groupKey, msg, sig); err != nil {
+		return nil, errors.New("aggregated signature is not valid, the threshold may not be reached")
+	}
+	return sig, nil
+}
+
+// bindingFactors returns the binding factor of every signer along with
+// the group commitment R = sum(D_i + rho_i * E_i)
+func bindingFactors(groupKey ristretto.Point, commitments []SigningCommitment, msg []byte) (map[uint32]ristretto.Scalar, ristretto.Point, error) {
+
+	msgHash := hash("msg", msg)
+	commHash := hash("com", encodeCommitments(commitments))
+	prefix := append(append(groupKey.Bytes(), msgHash...), commHash...)
+
+	rho := make(map[uint32]ristretto.Scalar, len(commitments))
+
+	var R ristretto.Point
+	R.SetZero()
+	for _, comm := range commitments {
+		x := identifierToScalar(comm.ID)
+		r := hashToScalar("rho", prefix, x.Bytes())
+		rho[comm.ID] = r
+
+		var rE ristretto.Point
+		rE.PublicScalarMult(&comm.Binding, &r)
+		R.Add(&R, &comm.Hiding)
+		R.Add(&R, &rE)
+	}
+
+	if isIdentity(R) {
+		return nil, R, errors.New("group commitment is the identity")
+	}
+	return rho, R, nil
+}
+
+// encodeCommitments serializes the sorted commitment list as
+// id || D_i || E_i for every signer
+func encodeCommitments(commitments []SigningCommitment) []byte {
+	buf := make([]byte, 0, len(commitments)*96)
+	for _, comm := range commitments {
+		x := identifierToScalar(comm.ID)
+		buf = append(buf, x.Bytes()...)
+		buf = append(buf, comm.Hiding.Bytes()...)
+		buf = append(buf, comm.Binding.Bytes()...)
+	}
+	return buf
+}
+
+// sortCommitments returns a copy of the commitments sorted by identifier,
+// rejecting duplicate or invalid ones
+func sortCommitments(commitments []SigningCommitment) ([]SigningCommitment, error) {
+
+	if len(commitments) == 0 {
+		return nil, errors.New("no signing commitments")
+	}
+
+	sorted := make([]SigningCommitment, len(commitments))
+	copy(sorted, commitments)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for i := range sorted {
+		if sorted[i].ID == 0 {
+			return nil, errors.New("identifier cannot be zero")
+		}
+		if i > 0 && sorted[i].ID == sorted[i-1].ID {
+			return nil, fmt.Errorf("duplicate commitment of participant %d", sorted[i].ID)
+		}
+		if isIdentity(sorted[i].Hiding) || isIdentity(sorted[i].Binding) {
+			return nil, fmt.Errorf("commitment of participant %d is the identity", sorted[i].ID)
+		}
+	}
+	return sorted, nil
+}
+
+func findCommitment(id uint32, commitments []SigningCommitment) (SigningCommitment, error) {
+	for _, comm := range commitments {
+		if comm.ID == id {
+			return comm, nil
+		}
+	}
+	return SigningCommitment{}, fmt.Errorf("participant %d has no signing commitment", id)
+}
+
+func identifiers(commitments []SigningCommitment) []uint32 {
+	ids := make([]uint32, len(commitments))
+	for i := range commitments {
+		ids[i] = commitments[i].ID
+	}
+	return ids
+}
@@ -0,0 +1,178 @@
+package frost
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/schnorr"
+)
+
+// participant is an in-memory signer
+type participant struct {
+	share  KeyShare
+	nonces *SigningNonces
+}
+
+func TestThresholdSign(t *testing.T) {
+	msg := []byte("hello world")
+
+	for _, tc := range []struct{ n, t int }{{1, 1}, {3, 2}, {5, 3}, {7, 7}} {
+		groupKey, pubKeyShares, shares := keygen(t, tc.n, tc.t)
+
+		// the last t participants sign
+		signers, commitments := roundOne(t, shares[tc.n-tc.t:])
+		sigShares := roundTwo(t, signers, commitments, msg)
+
+		for i := range sigShares {
+			err := VerifySignatureShare(sigShares[i], pubKeyShares[sigShares[i].ID], groupKey, commitments, msg)
+			assert.Nil(t, err)
+		}
+
+		sig, err := Aggregate(groupKey, pubKeyShares, commitments, msg, sigShares)
+		require.Nil(t, err)
+
+		ok, err := Verify(groupKey, msg, sig)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = Verify(groupKey, []byte("something random"), sig)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+
+		// the signature round trips through the schnorr encoding
+		b := sig.Bytes()
+		decoded := &schnorr.Signature{}
+		require.Nil(t, decoded.SetBytes(b[:]))
+		ok, err = Verify(groupKey, msg, decoded)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestIdentifiableAbort(t *testing.T) {
+	msg := []byte("hello world")
+	groupKey, pubKeyShares, shares := keygen(t, 5, 3)
+
+	signers, commitments := roundOne(t, []KeyShare{shares[0], shares[2], shares[4]})
+	sigShares := roundTwo(t, signers, commitments, msg)
+	sigShares[1].Z.Rand()
+
+	_, err := Aggregate(groupKey, pubKeyShares, commitments, msg, sigShares)
+	require.NotNil(t, err)
+	invalid, ok := err.(*InvalidShareError)
+	require.True(t, ok)
+	assert.Equal(t, uint32(3), invalid.ID)
+
+	err = VerifySignatureShare(sigShares[1], pubKeyShares[3], groupKey, commitments, msg)
+	assert.IsType(t, &InvalidShareError{}, err)
+
+	// a valid share claimed by another participant
+	sigShares = roundTwo(t, freshSigners(t, signers, commitments), commitments, msg)
+	sigShares[0].Z, sigShares[2].Z = sigShares[2].Z, sigShares[0].Z
+	_, err = Aggregate(groupKey, pubKeyShares, commitments, msg, sigShares)
+	assert.IsType(t, &InvalidShareError{}, err)
+}
+
+func TestNotEnoughSigners(t *testing.T) {
+	msg := []byte("hello world")
+	groupKey, pubKeyShares, shares := keygen(t, 5, 3)
+
+	// every share is valid, but two shares interpolate to the wrong key
+	signers, commitments := roundOne(t, shares[:2])
+	sigShares := roundTwo(t, signers, commitments, msg)
+
+	for i := range sigShares {
+		err := VerifySignatureShare(sigShares[i], pubKeyShares[sigShares[i].ID], groupKey, commitments, msg)
+		assert.Nil(t, err)
+	}
+
+	_, err := Aggregate(groupKey, pubKeyShares, commitments, msg, sigShares)
+	assert.NotNil(t, err)
+}
+
+func TestSignInvalid(t *testing.T) {
+	msg := []byte("hello world")
+	groupKey, pubKeyShares, shares := keygen(t, 3, 2)
+
+	signers, commitments := roundOne(t, shares[:2])
+
+	// signer without a commitment
+	_, err := Sign(shares[2], signers[0].nonces, commitments, msg)
+	assert.NotNil(t, err)
+
+	// nonces of another signer
+	_, err = Sign(shares[0], signers[1].nonces, commitments, msg)
+	assert.NotNil(t, err)
+
+	// duplicate commitments
+	_, err = Sign(shares[0], signers[0].nonces, append(commitments, commitments[0]), msg)
+	assert.NotNil(t, err)
+
+	_, err = Sign(shares[0], signers[0].nonces, nil, msg)
+	assert.NotNil(t, err)
+
+	// nonces can be used only once
+	sigShare, err := Sign(shares[0], signers[0].nonces, commitments, msg)
+	require.Nil(t, err)
+	_, err = Sign(shares[0], signers[0].nonces, commitments, []byte("something random"))
+	assert.NotNil(t, err)
+	_, err = Sign(shares[0], nil, commitments, msg)
+	assert.NotNil(t, err)
+
+	// missing, duplicate or unknown shares
+	_, err = Aggregate(groupKey, pubKeyShares, commitments, msg, []SignatureShare{sigShare})
+	assert.NotNil(t, err)
+	_, err = Aggregate(groupKey, pubKeyShares, commitments, msg, []SignatureShare{sigShare, sigShare})
+	assert.NotNil(t, err)
+	_, err = Aggregate(groupKey, map[uint32]ristretto.Point{}, commitments, msg, []SignatureShare{sigShare, sigShare})
+	assert.NotNil(t, err)
+}
+
+func keygen(t *testing.T, n, threshold int) (ristretto.Point, map[uint32]ristretto.Point, []KeyShare) {
+	var secret ristretto.Scalar
+	secret.Rand()
+
+	shares, vss, err := TrustedDealerKeygen(secret, n, threshold)
+	require.Nil(t, err)
+
+	pubKeyShares := make(map[uint32]ristretto.Point, n)
+	for i := range shares {
+		require.Nil(t, VerifyShare(shares[i], vss))
+		pubKeyShares[shares[i].ID] = vss.PubKeyShare(shares[i].ID)
+	}
+	return vss[0], pubKeyShares, shares
+}
+
+func roundOne(t *testing.T, shares []KeyShare) ([]participant, []SigningCommitment) {
+	signers := make([]participant, len(shares))
+	commitments := make([]SigningCommitment, len(shares))
+	for i := range shares {
+		var err error
+		signers[i].share = shares[i]
+		signers[i].nonces, commitments[i], err = Commit(shares[i])
+		require.Nil(t, err)
+	}
+	return signers, commitments
+}
+
+func freshSigners(t *testing.T, signers []participant, commitments []SigningCommitment) []participant {
+	shares := make([]KeyShare, len(signers))
+	for i := range signers {
+		shares[i] = signers[i].share
+	}
+	fresh, newCommitments := roundOne(t, shares)
+	copy(commitments, newCommitments)
+	return fresh
+}
+
+func roundTwo(t *testing.T, signers []participant, commitments []SigningCommitment, msg []byte) []SignatureShare {
+	sigShares := make([]SignatureShare, len(signers))
+	for i := range signers {
+		var err error
+		sigShares[i], err = Sign(signers[i].share, signers[i].nonces, commitments, msg)
+		require.Nil(t, err)
+	}
+	return sigShares
+}
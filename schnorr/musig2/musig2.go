@@ -0,0 +1,127 @@
+// Package musig2 implements MuSig2 n-of-n multi-signatures over ristretto.
+//
+// The public keys of all signers are aggregated into a single key
+// X = sum(a_i * P_i), where every coefficient a_i is bound to the whole
+// list of keys. The signers then run two rounds: they exchange two public
+// nonces each, and once every nonce is known they exchange partial
+// signatures. The sum of the partial signatures is an ordinary Schnorr
+// signature under X, which verifies with schnorr.VerifyWithDomain and cannot
+// be told apart from a single signer signature.
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+var (
+	keyListLabel = []byte("vosbor.musig2.keylist")
+	keyCoefLabel = []byte("vosbor.musig2.keycoef")
+)
+
+// KeyAggContext holds the aggregated public key of a set of signers,
+// along with the coefficient of every signer
+type KeyAggContext struct {
+	AggKey  ristretto.Point
+	pubKeys []ristretto.Point
+	coefs   []ristretto.Scalar
+}
+
+// AggregateKeys aggregates the public keys of the signers.
+// The order of the keys matters, all signers must use the same order
+func AggregateKeys(pubKeys []ristretto.Point) (*KeyAggContext, error) {
+
+	if len(pubKeys) == 0 {
+		return nil, errors.New("no public keys to aggregate")
+	}
+
+	for i := range pubKeys {
+		if isIdentity(pubKeys[i]) {
+			return nil, errors.New("public key cannot be the identity")
+		}
+		for j := 0; j < i; j++ {
+			if pubKeys[i].Equals(&pubKeys[j]) {
+				return nil, errors.New("public keys must be distinct")
+			}
+		}
+	}
+
+	ctx := &KeyAggContext{
+		pubKeys: make([]ristretto.Point, len(pubKeys)),
+		coefs:   make([]ristretto.Scalar, len(pubKeys)),
+	}
+	copy(ctx.pubKeys, pubKeys)
+
+	// L = H(P_1, ..., P_n)
+	var list []byte
+	for i := range pubKeys {
+		list = append(list, pubKeys[i].Bytes()...)
+	}
+	L := hashToScalar(keyListLabel, list)
+
+	// X = sum(a_i * P_i), a_i = H(L, P_i)
+	ctx.AggKey.SetZero()
+	for i := range pubKeys {
+		ctx.coefs[i] = hashToScalar(keyCoefLabel, L.Bytes(), pubKeys[i].Bytes())
+
+		var aP ristretto.Point
+		aP.PublicScalarMult(&pubKeys[i], &ctx.coefs[i])
+		ctx.AggKey.Add(&ctx.AggKey, &aP)
+	}
+
+	if isIdentity(ctx.AggKey) {
+		return nil, errors.New("aggregated key is the identity")
+	}
+	return ctx, nil
+}
+
+// PubKeys returns the public keys of the signers in order
+func (ctx *KeyAggContext) PubKeys() []ristretto.Point {
+	pubKeys := make([]ristretto.Point, len(ctx.pubKeys))
+	copy(pubKeys, ctx.pubKeys)
+	return pubKeys
+}
+
+// Coefficient returns the coefficient of a signer's public key
+func (ctx *KeyAggContext) Coefficient(pubKey ristretto.Point) (ristretto.Scalar, error) {
+	i, err := ctx.index(pubKey)
+	if err != nil {
+		return ristretto.Scalar{}, err
+	}
+	return ctx.coefs[i], nil
+}
+
+func (ctx *KeyAggContext) index(pubKey ristretto.Point) (int, error) {
+	for i := range ctx.pubKeys {
+		if ctx.pubKeys[i].Equals(&pubKey) {
+			return i, nil
+		}
+	}
+	return -1, errors.New("public key is not part of the aggregated key")
+}
+
+// hashToScalar hashes the length prefixed label and data into a scalar
+func hashToScalar(label []byte, data ...[]byte) ristretto.Scalar {
+	h := sha512.New()
+	for _, d := range append([][]byte{label}, data...) {
+		binary.Write(h, binary.BigEndian, uint32(len(d)))
+		h.Write(d)
+	}
+
+	var digest [64]byte
+	copy(digest[:], h.Sum(nil))
+
+	var s ristretto.Scalar
+	s.SetReduced(&digest)
+	return s
+}
+
+func isIdentity(p ristretto.Point) bool {
+	var buf [32]byte
+	p.BytesInto(&buf)
+	return bytes.Equal(buf[:], make([]byte, 32))
+}
@@ -0,0 +1,72 @@
+package musig2
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateKeys(t *testing.T) {
+	_, pubKeys := randKeys(4)
+
+	ctx, err := AggregateKeys(pubKeys)
+	require.Nil(t, err)
+
+	// deterministic
+	other, err := AggregateKeys(pubKeys)
+	require.Nil(t, err)
+	assert.True(t, ctx.AggKey.Equals(&other.AggKey))
+
+	// the order of the keys matters
+	swapped := ctx.PubKeys()
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	other, err = AggregateKeys(swapped)
+	require.Nil(t, err)
+	assert.False(t, ctx.AggKey.Equals(&other.AggKey))
+
+	// the aggregated key is not the plain sum of the keys
+	var sum ristretto.Point
+	sum.SetZero()
+	for i := range pubKeys {
+		sum.Add(&sum, &pubKeys[i])
+	}
+	assert.False(t, ctx.AggKey.Equals(&sum))
+
+	for i := range pubKeys {
+		a, err := ctx.Coefficient(pubKeys[i])
+		require.Nil(t, err)
+		assert.Equal(t, int32(1), a.IsNonZeroI())
+	}
+
+	var stranger ristretto.Point
+	stranger.Rand()
+	_, err = ctx.Coefficient(stranger)
+	assert.NotNil(t, err)
+}
+
+func TestAggregateKeysInvalid(t *testing.T) {
+	_, pubKeys := randKeys(3)
+
+	_, err := AggregateKeys(nil)
+	assert.NotNil(t, err)
+
+	_, err = AggregateKeys(append(pubKeys, pubKeys[1]))
+	assert.NotNil(t, err)
+
+	var identity ristretto.Point
+	identity.SetZero()
+	_, err = AggregateKeys(append(pubKeys, identity))
+	assert.NotNil(t, err)
+}
+
+func randKeys(n int) ([]ristretto.Scalar, []ristretto.Point) {
+	privKeys := make([]ristretto.Scalar, n)
+	pubKeys := make([]ristretto.Point, n)
+	for i := 0; i < n; i++ {
+		privKeys[i].Rand()
+		pubKeys[i].ScalarMultBase(&privKeys[i])
+	}
+	return privKeys, pubKeys
+}
@@ -0,0 +1,232 @@
+package musig2
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/schnorr"
+)
+
+// PublicNonceSize is the size of an encoded public nonce
+const PublicNonceSize = 64
+
+var (
+	secretNonceLabel = []byte("vosbor.musig2.secnonce")
+	nonceCoefLabel   = []byte("vosbor.musig2.noncecoef")
+)
+
+// SecretNonce is the secret part of a signer's nonce. It can be used for a
+// single partial signature, and is cleared once it has been used
+type SecretNonce struct {
+	k1, k2 ristretto.Scalar
+	pubKey ristretto.Point
+	used   bool
+}
+
+// PublicNonce is the pair of nonce commitments a signer sends in the
+// first round
+type PublicNonce struct {
+	R1, R2 ristretto.Point
+}
+
+// NewNonce draws a fresh nonce for the signer of privKey. The secret
+// nonce is kept by the signer and the public nonce is sent to the others
+func NewNonce(privKey ristretto.Scalar) (*SecretNonce, PublicNonce, error) {
+
+	if privKey.IsNonZeroI() == 0 {
+		return nil, PublicNonce{}, errors.New("private key cannot be zero")
+	}
+
+	sec := &SecretNonce{}
+	sec.pubKey.ScalarMultBase(&privKey)
+
+	// the randomness is hashed with the private key, so that a weak
+	// random source alone does not leak the nonces
+	var seed [32]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, PublicNonce{}, err
+	}
+	sec.k1 = hashToScalar(secretNonceLabel, seed[:], privKey.Bytes(), []byte{1})
+	sec.k2 = hashToScalar(secretNonceLabel, seed[:], privKey.Bytes(), []byte{2})
+
+	var pub PublicNonce
+	pub.R1.ScalarMultBase(&sec.k1)
+	pub.R2.ScalarMultBase(&sec.k2)
+
+	return sec, pub, nil
+}
+
+// Bytes returns the encoding R1 | R2
+func (n PublicNonce) Bytes() [PublicNonceSize]byte {
+	var b [PublicNonceSize]byte
+	copy(b[:32], n.R1.Bytes())
+	copy(b[32:], n.R2.Bytes())
+	return b
+}
+
+// SetBytes decodes the output of Bytes
+func (n *PublicNonce) SetBytes(b []byte) error {
+
+	if len(b) != PublicNonceSize {
+		return fmt.Errorf("public nonce must be %d bytes, got %d", PublicNonceSize, len(b))
+	}
+
+	var buf [32]byte
+	copy(buf[:], b[:32])
+	if !n.R1.SetBytes(&buf) {
+		return errors.New("R1 is not a valid point")
+	}
+	copy(buf[:], b[32:])
+	if !n.R2.SetBytes(&buf) {
+		return errors.New("R2 is not a valid point")
+	}
+	return nil
+}
+
+// Session is the state of the second round, shared by all signers once
+// every public nonce is known
+type Session struct {
+	keyAgg *KeyAggContext
+	nonces []PublicNonce
+	domain []byte
+	msg    []byte
+
+	// R = R1 + b * R2
+	R ristretto.Point
+	b ristretto.Scalar
+	e ristretto.Scalar
+}
+
+// NewSession starts the second round for msg in the default domain.
+// nonces holds the public nonce of every signer, in the order of the keys
+func NewSession(keyAgg *KeyAggContext, nonces []PublicNonce, msg []byte) (*Session, error) {
+	return NewSessionWithDomain(schnorr.DefaultDomain, keyAgg, nonces, msg)
+}
+
+// NewSessionWithDomain starts the second round for msg in the given domain
+func NewSessionWithDomain(domain []byte, keyAgg *KeyAggContext, nonces []PublicNonce, msg []byte) (*Session, error) {
+
+	if keyAgg == nil {
+		return nil, errors.New("key aggregation context is nil")
+	}
+	if len(nonces) != len(keyAgg.pubKeys) {
+		return nil, fmt.Errorf("expected %d public nonces, got %d", len(keyAgg.pubKeys), len(nonces))
+	}
+
+	s := &Session{
+		keyAgg: keyAgg,
+		nonces: make([]PublicNonce, len(nonces)),
+		domain: domain,
+		msg:    msg,
+	}
+	copy(s.nonces, nonces)
+
+	var R1, R2 ristretto.Point
+	R1.SetZero()
+	R2.SetZero()
+	for i := range nonces {
+		R1.Add(&R1, &nonces[i].R1)
+		R2.Add(&R2, &nonces[i].R2)
+	}
+
+	// b = H(X, R1, R2, domain, msg)
+	s.b = hashToScalar(nonceCoefLabel, keyAgg.AggKey.Bytes(), R1.Bytes(), R2.Bytes(), domain, msg)
+
+	var bR2 ristretto.Point
+	bR2.PublicScalarMult(&R2, &s.b)
+	s.R.Add(&R1, &bR2)
+
+	if isIdentity(s.R) {
+		return nil, errors.New("aggregated nonce is the identity")
+	}
+
+	s.e = schnorr.Challenge(domain, s.R, keyAgg.AggKey, msg)
+	return s, nil
+}
+
+// Sign creates the partial signature s_i = k1 + b * k2 + e * a_i * x_i.
+// The secret nonce is cleared, so that it can never be used twice
+func (s *Session) Sign(secNonce *SecretNonce, privKey ristretto.Scalar) (ristretto.Scalar, error) {
+
+	var partial ristretto.Scalar
+
+	if secNonce == nil || secNonce.used {
+		return partial, errors.New("secret nonce has already been used")
+	}
+
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+	if !pubKey.Equals(&secNonce.pubKey) {
+		return partial, errors.New("secret nonce was not created for this private key")
+	}
+
+	i, err := s.keyAgg.index(pubKey)
+	if err != nil {
+		return partial, err
+	}
+
+	var R1, R2 ristretto.Point
+	R1.ScalarMultBase(&secNonce.k1)
+	R2.ScalarMultBase(&secNonce.k2)
+	if !R1.Equals(&s.nonces[i].R1) || !R2.Equals(&s.nonces[i].R2) {
+		return partial, errors.New("secret nonce does not match the public nonce of the signer")
+	}
+
+	var ea ristretto.Scalar
+	ea.Mul(&s.e, &s.keyAgg.coefs[i])
+
+	partial.MulAdd(&s.b, &secNonce.k2, &secNonce.k1)
+	partial.MulAdd(&ea, &privKey, &partial)
+
+	secNonce.k1.SetZero()
+	secNonce.k2.SetZero()
+	secNonce.used = true
+
+	return partial, nil
+}
+
+// VerifyPartial checks the partial signature of the i-th signer,
+// s_i * G = R1_i + b * R2_i + e * a_i * P_i
+func (s *Session) VerifyPartial(i int, partial ristretto.Scalar) error {
+
+	if i < 0 || i >= len(s.nonces) {
+		return fmt.Errorf("signer index %d is out of range", i)
+	}
+
+	var ea ristretto.Scalar
+	ea.Mul(&s.e, &s.keyAgg.coefs[i])
+
+	var lhs, rhs, bR2, eaP ristretto.Point
+	lhs.ScalarMultBase(&partial)
+	bR2.PublicScalarMult(&s.nonces[i].R2, &s.b)
+	eaP.PublicScalarMult(&s.keyAgg.pubKeys[i], &ea)
+	rhs.Add(&s.nonces[i].R1, &bR2)
+	rhs.Add(&rhs, &eaP)
+
+	if !lhs.Equals(&rhs) {
+		return fmt.Errorf("partial signature of signer %d is not valid", i)
+	}
+	return nil
+}
+
+// Aggregate checks every partial signature and sums them into a Schnorr
+// signature under the aggregated key. An invalid partial signature is
+// reported along with the index of its signer
+func (s *Session) Aggregate(partials []ristretto.Scalar) (*schnorr.Signature, error) {
+
+	if len(partials) != len(s.nonces) {
+		return nil, fmt.Errorf("expected %d partial signatures, got %d", len(s.nonces), len(partials))
+	}
+
+	sig := &schnorr.Signature{R: s.R}
+	sig.S.SetZero()
+	for i := range partials {
+		if err := s.VerifyPartial(i, partials[i]); err != nil {
+			return nil, err
+		}
+		sig.S.Add(&sig.S, &partials[i])
+	}
+	return sig, nil
+}
@@ -0,0 +1,199 @@
+package musig2
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/schnorr"
+)
+
+// signer is an in-memory participant
+type signer struct {
+	privKey  ristretto.Scalar
+	secNonce *SecretNonce
+	pubNonce PublicNonce
+}
+
+func TestMultiSig(t *testing.T) {
+	msg := []byte("hello world")
+
+	for _, n := range []int{1, 2, 3, 5, 16} {
+		keyAgg, signers := setup(t, n)
+
+		session, err := NewSession(keyAgg, pubNonces(signers), msg)
+		require.Nil(t, err)
+
+		partials := make([]ristretto.Scalar, n)
+		for i := range signers {
+			partials[i], err = session.Sign(signers[i].secNonce, signers[i].privKey)
+			require.Nil(t, err)
+			assert.Nil(t, session.VerifyPartial(i, partials[i]))
+		}
+
+		sig, err := session.Aggregate(partials)
+		require.Nil(t, err)
+
+		// the result is a plain Schnorr signature under the aggregated key
+		ok, err := schnorr.Verify(keyAgg.AggKey, msg, sig)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = schnorr.Verify(keyAgg.AggKey, []byte("something random"), sig)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestMultiSigDomain(t *testing.T) {
+	domain := []byte("vosbor.rpc.auth")
+	msg := []byte("nonce:1234")
+	keyAgg, signers := setup(t, 3)
+
+	session, err := NewSessionWithDomain(domain, keyAgg, pubNonces(signers), msg)
+	require.Nil(t, err)
+
+	sig, err := session.Aggregate(signAll(t, session, signers))
+	require.Nil(t, err)
+
+	ok, err := schnorr.VerifyWithDomain(domain, keyAgg.AggKey, msg, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = schnorr.Verify(keyAgg.AggKey, msg, sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestInvalidPartial(t *testing.T) {
+	keyAgg, signers := setup(t, 4)
+
+	session, err := NewSession(keyAgg, pubNonces(signers), []byte("hello world"))
+	require.Nil(t, err)
+
+	partials := signAll(t, session, signers)
+	partials[2].Rand()
+
+	assert.NotNil(t, session.VerifyPartial(2, partials[2]))
+	assert.Nil(t, session.VerifyPartial(1, partials[1]))
+
+	// the misbehaving signer is identified
+	_, err = session.Aggregate(partials)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "signer 2")
+
+	// partials of another signer do not verify
+	assert.NotNil(t, session.VerifyPartial(0, partials[1]))
+
+	assert.NotNil(t, session.VerifyPartial(4, partials[1]))
+	_, err = session.Aggregate(partials[1:])
+	assert.NotNil(t, err)
+}
+
+func TestNonceReuse(t *testing.T) {
+	keyAgg, signers := setup(t, 2)
+
+	session, err := NewSession(keyAgg, pubNonces(signers), []byte("hello world"))
+	require.Nil(t, err)
+
+	_, err = session.Sign(signers[0].secNonce, signers[0].privKey)
+	require.Nil(t, err)
+
+	// a second message with the same secret nonce would leak the key
+	other, err := NewSession(keyAgg, pubNonces(signers), []byte("something random"))
+	require.Nil(t, err)
+	_, err = other.Sign(signers[0].secNonce, signers[0].privKey)
+	assert.NotNil(t, err)
+
+	_, err = other.Sign(nil, signers[0].privKey)
+	assert.NotNil(t, err)
+}
+
+func TestSignMismatch(t *testing.T) {
+	keyAgg, signers := setup(t, 3)
+
+	session, err := NewSession(keyAgg, pubNonces(signers), []byte("hello world"))
+	require.Nil(t, err)
+
+	// nonce of another signer
+	_, err = session.Sign(signers[1].secNonce, signers[0].privKey)
+	assert.NotNil(t, err)
+
+	// key outside of the aggregated key
+	var stranger ristretto.Scalar
+	stranger.Rand()
+	secNonce, _, err := NewNonce(stranger)
+	require.Nil(t, err)
+	_, err = session.Sign(secNonce, stranger)
+	assert.NotNil(t, err)
+
+	// secret nonce that was never announced
+	secNonce, _, err = NewNonce(signers[0].privKey)
+	require.Nil(t, err)
+	_, err = session.Sign(secNonce, signers[0].privKey)
+	assert.NotNil(t, err)
+
+	// wrong number of nonces
+	_, err = NewSession(keyAgg, pubNonces(signers)[1:], []byte("hello world"))
+	assert.NotNil(t, err)
+
+	var zero ristretto.Scalar
+	zero.SetZero()
+	_, _, err = NewNonce(zero)
+	assert.NotNil(t, err)
+}
+
+func TestPublicNonceEncoding(t *testing.T) {
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	_, pub, err := NewNonce(privKey)
+	require.Nil(t, err)
+
+	b := pub.Bytes()
+	var decoded PublicNonce
+	require.Nil(t, decoded.SetBytes(b[:]))
+	assert.True(t, pub.R1.Equals(&decoded.R1))
+	assert.True(t, pub.R2.Equals(&decoded.R2))
+
+	assert.NotNil(t, decoded.SetBytes(b[:63]))
+
+	for i := range b[32:] {
+		b[32+i] = 0xFF
+	}
+	assert.NotNil(t, decoded.SetBytes(b[:]))
+}
+
+func setup(t *testing.T, n int) (*KeyAggContext, []signer) {
+	privKeys, pubKeys := randKeys(n)
+
+	keyAgg, err := AggregateKeys(pubKeys)
+	require.Nil(t, err)
+
+	signers := make([]signer, n)
+	for i := range signers {
+		signers[i].privKey = privKeys[i]
+		signers[i].secNonce, signers[i].pubNonce, err = NewNonce(privKeys[i])
+		require.Nil(t, err)
+	}
+	return keyAgg, signers
+}
+
+func pubNonces(signers []signer) []PublicNonce {
+	nonces := make([]PublicNonce, len(signers))
+	for i := range signers {
+		nonces[i] = signers[i].pubNonce
+	}
+	return nonces
+}
+
+func signAll(t *testing.T, session *Session, signers []signer) []ristretto.Scalar {
+	partials := make([]ristretto.Scalar, len(signers))
+	for i := range signers {
+		var err error
+		partials[i], err = session.Sign(signers[i].secNonce, signers[i].privKey)
+		require.Nil(t, err)
+	}
+	return partials
+}
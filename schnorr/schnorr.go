@@ -0,0 +1,183 @@
+// Package schnorr implements Schnorr signatures over ristretto.
+//
+// Keys are the same scalars and points used by the ring signatures, P = x * G.
+// A signature on msg is (R, s) with R = k * G, s = k + e * x and
+// e = H(domain, R, P, msg). The nonce k is derived deterministically from the
+// private key, the domain and the message, so signing needs no randomness.
+// Every signature is bound to a domain, so that a signature created for one
+// purpose can never be replayed for another.
+package schnorr
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/fiatshamir"
+)
+
+// SignatureSize is the size of an encoded signature
+const SignatureSize = 64
+
+// DefaultDomain is the domain used by Sign and Verify
+var DefaultDomain = []byte("vosbor.schnorr.v1")
+
+var (
+	challengeLabel = []byte("vosbor.schnorr.challenge")
+	nonceLabel     = []byte("vosbor.schnorr.nonce")
+)
+
+// Signature is a Schnorr signature
+type Signature struct {
+	R ristretto.Point
+	S ristretto.Scalar
+}
+
+// Sign signs msg in the default domain
+func Sign(privKey ristretto.Scalar, msg []byte) (*Signature, error) {
+	return SignWithDomain(DefaultDomain, privKey, msg)
+}
+
+// Verify verifies a signature created with Sign
+func Verify(pubKey ristretto.Point, msg []byte, sig *Signature) (bool, error) {
+	return VerifyWithDomain(DefaultDomain, pubKey, msg, sig)
+}
+
+// SignWithDomain signs msg in the given domain
+func SignWithDomain(domain []byte, privKey ristretto.Scalar, msg []byte) (*Signature, error) {
+
+	if privKey.IsNonZeroI() == 0 {
+		return nil, errors.New("private key cannot be zero")
+	}
+
+	var P ristretto.Point
+	P.ScalarMultBase(&privKey)
+
+	k := nonce(domain, privKey, P, msg)
+
+	sig := &Signature{}
+	sig.R.ScalarMultBase(&k)
+
+	// s = k + e * x
+	e := Challenge(domain, sig.R, P, msg)
+	sig.S.MulAdd(&e, &privKey, &k)
+
+	return sig, nil
+}
+
+// VerifyWithDomain verifies a signature created with SignWithDomain
+func VerifyWithDomain(domain []byte, pubKey ristretto.Point, msg []byte, sig *Signature) (bool, error) {
+
+	if sig == nil {
+		return false, errors.New("signature is nil")
+	}
+	if isIdentity(pubKey) {
+		return false, errors.New("public key cannot be the identity")
+	}
+
+	e := Challenge(domain, sig.R, pubKey, msg)
+
+	// s * G = R + e * P
+	var lhs, rhs, eP ristretto.Point
+	lhs.ScalarMultBase(&sig.S)
+	eP.PublicScalarMult(&pubKey, &e)
+	rhs.Add(&sig.R, &eP)
+
+	if !lhs.Equals(&rhs) {
+		return false, errors.New("signature is not valid")
+	}
+	return true, nil
+}
+
+// Challenge returns e = H(domain, R, P, msg), the challenge of a
+// signature with nonce commitment R under the public key P
+func Challenge(domain []byte, R, P ristretto.Point, msg []byte) ristretto.Scalar {
+	t := fiatshamir.NewTranscript(challengeLabel)
+	t.AppendMessage([]byte("domain"), domain)
+	t.AppendPoint([]byte("R"), R)
+	t.AppendPoint([]byte("P"), P)
+	t.AppendMessage([]byte("msg"), msg)
+	return t.ChallengeScalar([]byte("e"))
+}
+
+// nonce derives k = H(label, domain, x, P, msg)
+func nonce(domain []byte, privKey ristretto.Scalar, P ristretto.Point, msg []byte) ristretto.Scalar {
+	h := sha512.New()
+	for _, data := range [][]byte{nonceLabel, domain, privKey.Bytes(), P.Bytes(), msg} {
+		binary.Write(h, binary.BigEndian, uint32(len(data)))
+		h.Write(data)
+	}
+
+	var digest [64]byte
+	copy(digest[:], h.Sum(nil))
+
+	var k ristretto.Scalar
+	k.SetReduced(&digest)
+	return k
+}
+
+// Bytes returns the canonical encoding R | s
+func (sig *Signature) Bytes() [SignatureSize]byte {
+	var b [SignatureSize]byte
+	copy(b[:32], sig.R.Bytes())
+	copy(b[32:], sig.S.Bytes())
+	return b
+}
+
+// SetBytes decodes the output of Bytes. R must be a valid point
+// and s must be fully reduced
+func (sig *Signature) SetBytes(b []byte) error {
+
+	if len(b) != SignatureSize {
+		return fmt.Errorf("signature must be %d bytes, got %d", SignatureSize, len(b))
+	}
+
+	var buf [32]byte
+	copy(buf[:], b[:32])
+	if !sig.R.SetBytes(&buf) {
+		return errors.New("R is not a valid point")
+	}
+
+	copy(buf[:], b[32:])
+	sig.S.SetBytes(&buf)
+	if !bytes.Equal(sig.S.Bytes(), buf[:]) {
+		return errors.New("s is not canonically encoded")
+	}
+	return nil
+}
+
+// Encode writes the canonical encoding of the signature
+func (sig *Signature) Encode(w io.Writer) error {
+	b := sig.Bytes()
+	_, err := w.Write(b[:])
+	return err
+}
+
+// Decode reads a signature written with Encode
+func (sig *Signature) Decode(r io.Reader) error {
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	var b [SignatureSize]byte
+	_, err := io.ReadFull(r, b[:])
+	if err != nil {
+		return err
+	}
+	return sig.SetBytes(b[:])
+}
+
+// Equals returns true if both signatures are the same
+func (sig *Signature) Equals(other *Signature) bool {
+	return sig.R.Equals(&other.R) && sig.S.Equals(&other.S)
+}
+
+func isIdentity(p ristretto.Point) bool {
+	var buf [32]byte
+	p.BytesInto(&buf)
+	return buf == [32]byte{}
+}
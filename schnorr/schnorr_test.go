@@ -0,0 +1,229 @@
+package schnorr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Known answers pinning the nonce derivation, the challenge and the encoding.
+// Changing any of them breaks every signature made so far
+var knownAnswers = []struct {
+	privKey, pubKey string
+	domain, msg     string
+	sig             string
+}{
+	{
+		privKey: "8d9622274359e744fbeca13af750f364f3f065140ef540c268d1dd655ff4db0c",
+		pubKey:  "228c71c5aee1cb5dcc5ab96e0f1a4fedf73204c70d4aed7260b4fd4a0bf82816",
+		domain:  "vosbor.schnorr.v1",
+		msg:     "",
+		sig:     "04ffba0c35f8e8f8f93a2b00a36727c5f406c1d7cb5f5c77fa01b141fa0425002aab81f49a97110ba2da56d5e422d3ac6f2701ec5cf76c43d73b11a88aa99d0a",
+	},
+	{
+		privKey: "8d9622274359e744fbeca13af750f364f3f065140ef540c268d1dd655ff4db0c",
+		pubKey:  "228c71c5aee1cb5dcc5ab96e0f1a4fedf73204c70d4aed7260b4fd4a0bf82816",
+		domain:  "vosbor.schnorr.v1",
+		msg:     "hello world",
+		sig:     "3cf830e6e1f49f49988ab6454061d71b3d5f4ceedae3c8dd011ac38387223c7102794ede2dbd7f9ffa12e3ae31e262967dfca53b842bff6744b774b296a82606",
+	},
+	{
+		privKey: "6cc22838c1ba7edacaa1bc087f93a92ca1f2ad5b28137a450a390d099dd6180a",
+		pubKey:  "68df2cf9bd64b10c8173b6110b50b3ee86e1265d713adc3022a7a138580d1658",
+		domain:  "vosbor.rpc.auth",
+		msg:     "nonce:1234",
+		sig:     "e6cc7a6d556b5c7cc89f0b8e35fc936d0cdfb873089e5880cfb298ba192cfc74727a24c330642ee87055348c2f3aca7c360597ef26d0af61fa881ec9eeb02704",
+	},
+}
+
+func TestKnownAnswers(t *testing.T) {
+	for _, ka := range knownAnswers {
+		privKey := hexToScalar(t, ka.privKey)
+		pubKey := hexToPoint(t, ka.pubKey)
+
+		var P ristretto.Point
+		P.ScalarMultBase(&privKey)
+		assert.True(t, P.Equals(&pubKey))
+
+		sig, err := SignWithDomain([]byte(ka.domain), privKey, []byte(ka.msg))
+		require.Nil(t, err)
+
+		b := sig.Bytes()
+		assert.Equal(t, ka.sig, hex.EncodeToString(b[:]))
+
+		expected := &Signature{}
+		err = expected.SetBytes(hexToBytes(t, ka.sig))
+		require.Nil(t, err)
+
+		ok, err := VerifyWithDomain([]byte(ka.domain), pubKey, []byte(ka.msg), expected)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	privKey, pubKey := randKey()
+	msg := []byte("hello world")
+
+	sig, err := Sign(privKey, msg)
+	require.Nil(t, err)
+
+	ok, err := Verify(pubKey, msg, sig)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// Nonces are deterministic
+	other, err := Sign(privKey, msg)
+	require.Nil(t, err)
+	assert.True(t, sig.Equals(other))
+}
+
+func TestBadSig(t *testing.T) {
+	privKey, pubKey := randKey()
+	_, otherKey := randKey()
+	msg := []byte("hello world")
+
+	sig, err := Sign(privKey, msg)
+	require.Nil(t, err)
+
+	ok, err := Verify(pubKey, []byte("something random"), sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	ok, err = Verify(otherKey, msg, sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	// Signatures do not cross domains
+	ok, err = VerifyWithDomain([]byte("vosbor.rpc.auth"), pubKey, msg, sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	tampered := *sig
+	tampered.S.Rand()
+	ok, err = Verify(pubKey, msg, &tampered)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	var identity ristretto.Point
+	identity.SetZero()
+	ok, err = Verify(identity, msg, sig)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	ok, err = Verify(pubKey, msg, nil)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestZeroKey(t *testing.T) {
+	var zero ristretto.Scalar
+	zero.SetZero()
+
+	_, err := Sign(zero, []byte("hello world"))
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	privKey, _ := randKey()
+
+	sig, err := Sign(privKey, []byte("hello world"))
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	err = sig.Encode(buf)
+	require.Nil(t, err)
+	assert.Equal(t, SignatureSize, buf.Len())
+
+	decodedSig := &Signature{}
+	err = decodedSig.Decode(buf)
+	require.Nil(t, err)
+	assert.True(t, sig.Equals(decodedSig))
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	privKey, _ := randKey()
+
+	sig, err := Sign(privKey, []byte("hello world"))
+	require.Nil(t, err)
+	b := sig.Bytes()
+
+	// order of the group, a non canonical encoding of zero
+	order := hexToBytes(t, "edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+
+	nonCanonical := b
+	copy(nonCanonical[32:], order)
+
+	invalidR := b
+	copy(invalidR[:32], bytes.Repeat([]byte{0xFF}, 32))
+
+	for name, malformed := range map[string][]byte{
+		"non canonical s": nonCanonical[:],
+		"invalid R":       invalidR[:],
+		"short":           b[:63],
+		"long":            append(b[:], 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := (&Signature{}).SetBytes(malformed)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	privKey, _ := randKey()
+	msg := []byte("hello world")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sign(privKey, msg)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	privKey, pubKey := randKey()
+	msg := []byte("hello world")
+	sig, _ := Sign(privKey, msg)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pubKey, msg, sig)
+	}
+}
+
+func randKey() (ristretto.Scalar, ristretto.Point) {
+	var privKey ristretto.Scalar
+	privKey.Rand()
+
+	var pubKey ristretto.Point
+	pubKey.ScalarMultBase(&privKey)
+	return privKey, pubKey
+}
+
+func hexToBytes(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.Nil(t, err)
+	return b
+}
+
+func hexToScalar(t *testing.T, s string) ristretto.Scalar {
+	var buf [32]byte
+	copy(buf[:], hexToBytes(t, s))
+
+	var x ristretto.Scalar
+	x.SetBytes(&buf)
+	return x
+}
+
+func hexToPoint(t *testing.T, s string) ristretto.Point {
+	var buf [32]byte
+	copy(buf[:], hexToBytes(t, s))
+
+	var p ristretto.Point
+	require.True(t, p.SetBytes(&buf))
+	return p
+}
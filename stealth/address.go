@@ -0,0 +1,109 @@
+package stealth
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/hash"
+)
+
+// AddressV1 is the version of the text encoding of an address
+const AddressV1 uint8 = 1
+
+// addressSize is version + view key + spend key + checksum
+const addressSize = 1 + 32 + 32 + 4
+
+// Address is the public view key A and spend key B of a recipient
+type Address struct {
+	View  ristretto.Point
+	Spend ristretto.Point
+}
+
+// Equals returns true if both addresses hold the same keys
+func (a Address) Equals(other Address) bool {
+	return a.View.Equals(&other.View) && a.Spend.Equals(&other.Spend)
+}
+
+// Bytes returns version | A | B | checksum, where the checksum
+// covers everything before it
+func (a Address) Bytes() []byte {
+	buf := make([]byte, 0, addressSize)
+	buf = append(buf, AddressV1)
+	buf = append(buf, a.View.Bytes()...)
+	buf = append(buf, a.Spend.Bytes()...)
+
+	// Xxhash cannot fail
+	checksum, _ := hash.Checksum(buf)
+
+	var c [4]byte
+	binary.BigEndian.PutUint32(c[:], checksum)
+	return append(buf, c[:]...)
+}
+
+// String returns the text encoding of the address
+func (a Address) String() string {
+	return base64.RawURLEncoding.EncodeToString(a.Bytes())
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (a *Address) UnmarshalText(text []byte) error {
+	addr, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
+}
+
+// ParseAddress decodes the text encoding of an address,
+// verifying its version, checksum and keys
+func ParseAddress(s string) (Address, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Address{}, err
+	}
+	return AddressFromBytes(b)
+}
+
+// AddressFromBytes decodes the output of Bytes
+func AddressFromBytes(b []byte) (Address, error) {
+
+	if len(b) != addressSize {
+		return Address{}, fmt.Errorf("address must be %d bytes, got %d", addressSize, len(b))
+	}
+	if b[0] != AddressV1 {
+		return Address{}, fmt.Errorf("unsupported address version %d", b[0])
+	}
+
+	payload := b[:addressSize-4]
+	if !hash.CompareChecksum(payload, binary.BigEndian.Uint32(b[addressSize-4:])) {
+		return Address{}, errors.New("address checksum does not match")
+	}
+
+	var addr Address
+	var key [32]byte
+
+	copy(key[:], payload[1:33])
+	if !addr.View.SetBytes(&key) {
+		return Address{}, errors.New("view key is not a valid point")
+	}
+	copy(key[:], payload[33:65])
+	if !addr.Spend.SetBytes(&key) {
+		return Address{}, errors.New("spend key is not a valid point")
+	}
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if addr.View.Equals(&zero) || addr.Spend.Equals(&zero) {
+		return Address{}, errors.New("address keys cannot be the identity")
+	}
+	return addr, nil
+}
@@ -0,0 +1,68 @@
+package stealth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressEncoding(t *testing.T) {
+	addr := NewPrivateKey().Address()
+
+	s := addr.String()
+	decoded, err := ParseAddress(s)
+	require.Nil(t, err)
+	assert.True(t, addr.Equals(decoded))
+
+	// Addresses can be embedded in JSON documents
+	data, err := json.Marshal(map[string]Address{"to": addr})
+	require.Nil(t, err)
+
+	var doc map[string]Address
+	err = json.Unmarshal(data, &doc)
+	require.Nil(t, err)
+	assert.True(t, addr.Equals(doc["to"]))
+}
+
+func TestAddressMalformed(t *testing.T) {
+	addr := NewPrivateKey().Address()
+
+	var identity ristretto.Point
+	identity.SetZero()
+
+	malform := map[string]func(b []byte) []byte{
+		"checksum": func(b []byte) []byte {
+			b[len(b)-1] ^= 1
+			return b
+		},
+		"view key": func(b []byte) []byte {
+			b[5] ^= 1
+			return b
+		},
+		"version": func(b []byte) []byte {
+			b[0] = AddressV1 + 1
+			return b
+		},
+		"truncated": func(b []byte) []byte {
+			return b[:len(b)-1]
+		},
+		"identity spend key": func(b []byte) []byte {
+			return Address{View: addr.View, Spend: identity}.Bytes()
+		},
+	}
+
+	for name, fn := range malform {
+		t.Run(name, func(t *testing.T) {
+			b := fn(addr.Bytes())
+			_, err := ParseAddress(base64.RawURLEncoding.EncodeToString(b))
+			assert.NotNil(t, err)
+		})
+	}
+
+	_, err := ParseAddress("not an address!")
+	assert.NotNil(t, err)
+}
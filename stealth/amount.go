@@ -0,0 +1,91 @@
+package stealth
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+// EncryptedAmountSize is the size of an encrypted amount and blinder
+const EncryptedAmountSize = 8 + 32
+
+var (
+	domainAmount  = []byte("vosbor.stealth.amount")
+	domainBlinder = []byte("vosbor.stealth.blinder")
+
+	// genData matches the rangeproof, so that the commitments
+	// of the outputs can be reopened
+	genData = []byte("vosbor.BulletProof.v1")
+)
+
+// ErrCommitmentMismatch is returned when the decrypted amount and
+// blinder do not open the commitment of the output
+var ErrCommitmentMismatch = errors.New("decrypted amount and blinder do not open the commitment")
+
+// EncryptedAmount is the amount and blinder of an output, encrypted
+// for the recipient: amount ^ mask (8 bytes) | blinder + Hs(shared || i) (32 bytes)
+type EncryptedAmount [EncryptedAmountSize]byte
+
+// EncryptAmount encrypts the amount and blinder of the output at index,
+// paying to the given address
+func (t *TxKey) EncryptAmount(addr Address, index uint32, amount uint64, blinder ristretto.Scalar) EncryptedAmount {
+	shared := t.sharedSecret(addr)
+
+	var enc EncryptedAmount
+	binary.BigEndian.PutUint64(enc[:8], amount^amountMask(shared, index))
+
+	mask := hashToScalar(domainBlinder, shared, index)
+	var b ristretto.Scalar
+	b.Add(&blinder, &mask)
+	copy(enc[8:], b.Bytes())
+
+	return enc
+}
+
+// DecryptAmount recovers the amount and blinder of the output at index of the
+// transaction with key R, and checks they open the commitment of the output
+func (vk ViewKey) DecryptAmount(R ristretto.Point, index uint32, enc EncryptedAmount, commitment ristretto.Point) (uint64, ristretto.Scalar, error) {
+	var shared ristretto.Point
+	shared.ScalarMult(&R, &vk.View)
+
+	amount := binary.BigEndian.Uint64(enc[:8]) ^ amountMask(shared, index)
+
+	var buf [32]byte
+	copy(buf[:], enc[8:])
+
+	var blinder ristretto.Scalar
+	blinder.SetBytes(&buf)
+	mask := hashToScalar(domainBlinder, shared, index)
+	blinder.Sub(&blinder, &mask)
+
+	var v ristretto.Scalar
+	v.SetBigInt(new(big.Int).SetUint64(amount))
+
+	ped := pedersen.New(genData)
+	c := pedersen.Commitment{
+		Commit:         commitment,
+		BlindingFactor: blinder,
+	}
+	if !ped.VerifyCommitment(v, c) {
+		return 0, ristretto.Scalar{}, ErrCommitmentMismatch
+	}
+	return amount, blinder, nil
+}
+
+// DecryptAmount recovers the amount and blinder of an output, see ViewKey.DecryptAmount
+func (k *PrivateKey) DecryptAmount(R ristretto.Point, index uint32, enc EncryptedAmount, commitment ristretto.Point) (uint64, ristretto.Scalar, error) {
+	return k.ViewKey().DecryptAmount(R, index, enc, commitment)
+}
+
+// amountMask returns the first 8 bytes of H(domain || shared || index)
+func amountMask(shared ristretto.Point, index uint32) uint64 {
+	h := sha512.New()
+	h.Write(domainAmount)
+	h.Write(shared.Bytes())
+	binary.Write(h, binary.BigEndian, index)
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
@@ -0,0 +1,71 @@
+package stealth
+
+import (
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/rangeproof/pedersen"
+)
+
+func TestEncryptAmount(t *testing.T) {
+	alice := NewPrivateKey()
+	ped := pedersen.New(genData)
+
+	for _, amount := range []uint64{0, 1, 1000, ^uint64(0)} {
+		txKey := NewTxKey()
+		commitment := commitToAmount(ped, amount)
+
+		enc := txKey.EncryptAmount(alice.Address(), 3, amount, commitment.BlindingFactor)
+		assert.Equal(t, EncryptedAmountSize, len(enc))
+
+		decAmount, blinder, err := alice.DecryptAmount(txKey.PubKey(), 3, enc, commitment.Commit)
+		require.Nil(t, err)
+		assert.Equal(t, amount, decAmount)
+		assert.True(t, blinder.Equals(&commitment.BlindingFactor))
+
+		// The view key alone can read the amount
+		decAmount, _, err = alice.ViewKey().DecryptAmount(txKey.PubKey(), 3, enc, commitment.Commit)
+		require.Nil(t, err)
+		assert.Equal(t, amount, decAmount)
+	}
+}
+
+func TestDecryptAmountFails(t *testing.T) {
+	alice := NewPrivateKey()
+	ped := pedersen.New(genData)
+
+	txKey := NewTxKey()
+	R := txKey.PubKey()
+	commitment := commitToAmount(ped, 500)
+	enc := txKey.EncryptAmount(alice.Address(), 0, 500, commitment.BlindingFactor)
+
+	// another recipient
+	_, _, err := NewPrivateKey().DecryptAmount(R, 0, enc, commitment.Commit)
+	assert.Equal(t, ErrCommitmentMismatch, err)
+
+	// another output index
+	_, _, err = alice.DecryptAmount(R, 1, enc, commitment.Commit)
+	assert.Equal(t, ErrCommitmentMismatch, err)
+
+	// another commitment
+	other := commitToAmount(ped, 500)
+	_, _, err = alice.DecryptAmount(R, 0, enc, other.Commit)
+	assert.Equal(t, ErrCommitmentMismatch, err)
+
+	// tampered ciphertext
+	for _, i := range []int{0, 7, 8, 39} {
+		tampered := enc
+		tampered[i] ^= 1
+		_, _, err = alice.DecryptAmount(R, 0, tampered, commitment.Commit)
+		assert.Equal(t, ErrCommitmentMismatch, err)
+	}
+}
+
+func commitToAmount(ped *pedersen.Pedersen, amount uint64) pedersen.Commitment {
+	var v ristretto.Scalar
+	v.SetBigInt(new(big.Int).SetUint64(amount))
+	return ped.CommitToScalar(v)
+}
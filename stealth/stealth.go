@@ -0,0 +1,156 @@
+// Package stealth implements dual-key stealth addresses.
+//
+// A recipient publishes an address made of a view key A = a * G and a spend
+// key B = b * G. For every transaction the sender picks a random r and publishes
+// the transaction key R = r * G. The one-time key of the output at index i is
+//
+//	P = Hs(r * A || i) * G + B
+//
+// The recipient recognises its outputs with the view key alone, as r * A = a * R,
+// and recovers the one-time secret key x = Hs(a * R || i) + b, with P = x * G,
+// which is needed to spend the output.
+package stealth
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+var (
+	domainOneTimeKey = []byte("vosbor.stealth.onetimekey")
+)
+
+// PrivateKey holds the private view key a and the private spend key b
+type PrivateKey struct {
+	View  ristretto.Scalar
+	Spend ristretto.Scalar
+}
+
+// NewPrivateKey returns a random private key
+func NewPrivateKey() *PrivateKey {
+	k := &PrivateKey{}
+	k.View.Rand()
+	k.Spend.Rand()
+	return k
+}
+
+// Address returns the public address of the private key
+func (k *PrivateKey) Address() Address {
+	var addr Address
+	addr.View.ScalarMultBase(&k.View)
+	addr.Spend.ScalarMultBase(&k.Spend)
+	return addr
+}
+
+// ViewKey returns the key needed to scan for outputs, which
+// cannot be used to spend them
+func (k *PrivateKey) ViewKey() ViewKey {
+	var spend ristretto.Point
+	spend.ScalarMultBase(&k.Spend)
+	return ViewKey{
+		View:  k.View,
+		Spend: spend,
+	}
+}
+
+// OneTimeSecret returns the private key of the one-time key at index
+// of the transaction with key R. The key can be passed directly to
+// mlsag.DualKey.SetPrimaryKey
+func (k *PrivateKey) OneTimeSecret(R ristretto.Point, index uint32) ristretto.Scalar {
+	var shared ristretto.Point
+	shared.ScalarMult(&R, &k.View)
+
+	// x = Hs(aR || i) + b
+	x := hashToScalar(domainOneTimeKey, shared, index)
+	x.Add(&x, &k.Spend)
+	return x
+}
+
+// ViewKey holds the private view key a and the public spend key B
+type ViewKey struct {
+	View  ristretto.Scalar
+	Spend ristretto.Point
+}
+
+// Owns returns true if the one-time key P at index of the
+// transaction with key R belongs to the view key
+func (vk ViewKey) Owns(R, P ristretto.Point, index uint32) bool {
+	var shared ristretto.Point
+	shared.ScalarMult(&R, &vk.View)
+
+	expected := oneTimeKey(shared, vk.Spend, index)
+	return expected.Equals(&P)
+}
+
+// Scan returns the indices of the one-time keys of the
+// transaction with key R which belong to the view key
+func (vk ViewKey) Scan(R ristretto.Point, oneTimeKeys []ristretto.Point) []uint32 {
+	var shared ristretto.Point
+	shared.ScalarMult(&R, &vk.View)
+
+	var owned []uint32
+	for i := range oneTimeKeys {
+		expected := oneTimeKey(shared, vk.Spend, uint32(i))
+		if expected.Equals(&oneTimeKeys[i]) {
+			owned = append(owned, uint32(i))
+		}
+	}
+	return owned
+}
+
+// TxKey is the random key r of a transaction, whose public key
+// R = r * G is published alongside the outputs
+type TxKey struct {
+	r ristretto.Scalar
+}
+
+// NewTxKey returns a random transaction key
+func NewTxKey() *TxKey {
+	t := &TxKey{}
+	t.r.Rand()
+	return t
+}
+
+// PubKey returns the transaction public key R = r * G
+func (t *TxKey) PubKey() ristretto.Point {
+	var R ristretto.Point
+	R.ScalarMultBase(&t.r)
+	return R
+}
+
+// OneTimeKey returns the one-time key of the output at
+// index, paying to the given address
+func (t *TxKey) OneTimeKey(addr Address, index uint32) ristretto.Point {
+	return oneTimeKey(t.sharedSecret(addr), addr.Spend, index)
+}
+
+// sharedSecret returns r * A
+func (t *TxKey) sharedSecret(addr Address) ristretto.Point {
+	var shared ristretto.Point
+	shared.ScalarMult(&addr.View, &t.r)
+	return shared
+}
+
+// oneTimeKey returns P = Hs(shared || i) * G + B
+func oneTimeKey(shared, spend ristretto.Point, index uint32) ristretto.Point {
+	s := hashToScalar(domainOneTimeKey, shared, index)
+
+	var P ristretto.Point
+	P.ScalarMultBase(&s)
+	P.Add(&P, &spend)
+	return P
+}
+
+// hashToScalar returns Hs(domain || shared || index)
+func hashToScalar(domain []byte, shared ristretto.Point, index uint32) ristretto.Scalar {
+	buf := new(bytes.Buffer)
+	buf.Write(domain)
+	buf.Write(shared.Bytes())
+	binary.Write(buf, binary.BigEndian, index)
+
+	var s ristretto.Scalar
+	s.Derive(buf.Bytes())
+	return s
+}
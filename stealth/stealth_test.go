@@ -0,0 +1,90 @@
+package stealth
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vosbor/dusk-crypto/mlsag"
+)
+
+func TestOneTimeKeys(t *testing.T) {
+	alice := NewPrivateKey()
+	bob := NewPrivateKey()
+
+	txKey := NewTxKey()
+	R := txKey.PubKey()
+
+	// outputs 0 and 2 pay alice, output 1 pays bob
+	outputs := []ristretto.Point{
+		txKey.OneTimeKey(alice.Address(), 0),
+		txKey.OneTimeKey(bob.Address(), 1),
+		txKey.OneTimeKey(alice.Address(), 2),
+	}
+
+	assert.Equal(t, []uint32{0, 2}, alice.ViewKey().Scan(R, outputs))
+	assert.Equal(t, []uint32{1}, bob.ViewKey().Scan(R, outputs))
+
+	assert.True(t, alice.ViewKey().Owns(R, outputs[2], 2))
+	assert.False(t, alice.ViewKey().Owns(R, outputs[2], 0))
+	assert.False(t, bob.ViewKey().Owns(R, outputs[0], 0))
+
+	// The recovered secret opens the one-time key
+	for _, i := range []uint32{0, 2} {
+		x := alice.OneTimeSecret(R, i)
+
+		var P ristretto.Point
+		P.ScalarMultBase(&x)
+		assert.True(t, P.Equals(&outputs[i]))
+	}
+}
+
+func TestOneTimeKeysAreUnlinkable(t *testing.T) {
+	alice := NewPrivateKey()
+	addr := alice.Address()
+
+	// The same address gets distinct keys across outputs and transactions
+	txKey := NewTxKey()
+	a := txKey.OneTimeKey(addr, 0)
+	b := txKey.OneTimeKey(addr, 1)
+	c := NewTxKey().OneTimeKey(addr, 0)
+
+	assert.False(t, a.Equals(&b))
+	assert.False(t, a.Equals(&c))
+	assert.False(t, a.Equals(&addr.Spend))
+}
+
+func TestSpendWithDualKey(t *testing.T) {
+	alice := NewPrivateKey()
+
+	txKey := NewTxKey()
+	P := txKey.OneTimeKey(alice.Address(), 0)
+	x := alice.OneTimeSecret(txKey.PubKey(), 0)
+
+	dk := mlsag.NewDualKey()
+	for i := 0; i < 5; i++ {
+		var keys mlsag.PubKeys
+		for j := 0; j < 2; j++ {
+			var p ristretto.Point
+			p.Rand()
+			keys.AddPubKey(p)
+		}
+		dk.AddDecoy(keys)
+	}
+
+	var commToZero ristretto.Scalar
+	commToZero.Rand()
+
+	pubKey := dk.SetPrimaryKey(x)
+	assert.True(t, pubKey.Equals(&P))
+	dk.SetCommToZero(commToZero)
+	dk.SetMsg([]byte("hello world"))
+
+	sig, keyImage, err := dk.Prove()
+	require.Nil(t, err)
+
+	ok, err := sig.Verify([]ristretto.Point{keyImage})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}